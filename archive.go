@@ -0,0 +1,143 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveTimestampLayouts are the renderings of timestamptz values found in archives
+var archiveTimestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700 -0700",
+	time.RFC3339Nano,
+}
+
+// radarArchive is a radar ZIP archive loaded into memory for offline analysis
+type radarArchive struct {
+	Path      string
+	Files     map[string][]byte
+	Names     []string  // Sorted entry names
+	Collected time.Time // Latest entry modification time
+}
+
+// loadArchive reads every entry of a radar archive. Query results written
+// with --query-format csv or ndjson are converted to TSV and named .tsv, so
+// that analysis reads archives of every format alike.
+func loadArchive(archivePath string) (*radarArchive, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer closeErrCheck(reader, "archive")
+
+	files := make(map[string][]byte)
+	var collected time.Time
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		closeErrCheck(rc, f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		name := f.Name
+		// Query results written as CSV or NDJSON are read as TSV; csvlog
+		// files are server logs, not query results
+		format := strings.TrimPrefix(path.Ext(name), ".")
+		if (format == QueryFormatCSV || format == QueryFormatNDJSON) && !strings.HasPrefix(name, LogArchiveDir+"/") {
+			if converted, err := queryOutputToTSV(data, format); err == nil {
+				name, data = strings.TrimSuffix(name, "."+format)+".tsv", converted
+			}
+		}
+		files[name] = data
+		if f.Modified.After(collected) {
+			collected = f.Modified
+		}
+	}
+	return newRadarArchive(archivePath, files, collected), nil
+}
+
+// newRadarArchive wraps archive entries already held in memory.
+func newRadarArchive(archivePath string, files map[string][]byte, collected time.Time) *radarArchive {
+	a := &radarArchive{Path: archivePath, Files: files, Collected: collected}
+	for name := range files {
+		a.Names = append(a.Names, name)
+	}
+	sort.Strings(a.Names)
+	return a
+}
+
+// text returns an entry's content, or "" if it is missing.
+func (a *radarArchive) text(name string) string {
+	return string(a.Files[name])
+}
+
+// table parses a TSV entry. It reports false if the entry is missing or malformed.
+func (a *radarArchive) table(name string) (*tsvTable, bool) {
+	data, ok := a.Files[name]
+	if !ok {
+		return nil, false
+	}
+	t, err := parseTSV(data)
+	if err != nil {
+		return nil, false
+	}
+	return t, true
+}
+
+// glob returns the sorted entry names matching a path.Match pattern.
+func (a *radarArchive) glob(pattern string) []string {
+	var matches []string
+	for _, name := range a.Names {
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// value returns the first row's value of a column in a TSV entry.
+func (a *radarArchive) value(name, column string) (string, bool) {
+	t, ok := a.table(name)
+	if !ok || len(t.Rows) == 0 {
+		return "", false
+	}
+	i := t.columnIndex(column)
+	if i < 0 || i >= len(t.Rows[0]) {
+		return "", false
+	}
+	return t.Rows[0][i], true
+}
+
+// parseArchiveTimestamp parses a timestamptz value as written to an archive.
+func parseArchiveTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range archiveTimestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,216 @@
+//go:build linux
+
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ClockTicksPerSecond is USER_HZ, the unit of utime/stime in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux architecture.
+const ClockTicksPerSecond = 100
+
+// backendActivityQuery lists the backends whose processes are inspected
+const backendActivityQuery = `SELECT pid, backend_type, datname, usename, state, wait_event_type, wait_event
+FROM pg_stat_activity ORDER BY pid`
+
+// backendActivityColumns are the pg_stat_activity columns joined to the OS stats
+var backendActivityColumns = []string{"pid", "backend_type", "datname", "usename", "state", "wait_event_type", "wait_event"}
+
+// backendOSColumns are read from /proc/<pid>; values that cannot be read are left empty
+var backendOSColumns = []string{
+	"proc_state", "utime_s", "stime_s", "minflt", "majflt", "threads",
+	"vm_rss_kb", "vm_hwm_kb", "rss_anon_kb", "rss_file_kb", "rss_shmem_kb", "vm_swap_kb",
+	"pss_kb", "pss_anon_kb", "pss_shmem_kb", "private_dirty_kb",
+	"voluntary_ctxt_switches", "nonvoluntary_ctxt_switches",
+	"rchar", "wchar", "read_bytes", "write_bytes", "cancelled_write_bytes",
+	"oom_score", "wchan", "fd_count",
+}
+
+// Fields taken from /proc/<pid>/status, /proc/<pid>/smaps_rollup and /proc/<pid>/io
+var (
+	backendStatusFields = map[string]string{
+		"VmRSS": "vm_rss_kb", "VmHWM": "vm_hwm_kb", "RssAnon": "rss_anon_kb", "RssFile": "rss_file_kb",
+		"RssShmem": "rss_shmem_kb", "VmSwap": "vm_swap_kb", "Threads": "threads",
+		"voluntary_ctxt_switches": "voluntary_ctxt_switches", "nonvoluntary_ctxt_switches": "nonvoluntary_ctxt_switches",
+	}
+	backendSmapsFields = map[string]string{
+		"Pss": "pss_kb", "Pss_Anon": "pss_anon_kb", "Pss_Shmem": "pss_shmem_kb", "Private_Dirty": "private_dirty_kb",
+	}
+	backendIOFields = map[string]string{
+		"rchar": "rchar", "wchar": "wchar", "read_bytes": "read_bytes",
+		"write_bytes": "write_bytes", "cancelled_write_bytes": "cancelled_write_bytes",
+	}
+)
+
+// getBackendOSTasks returns the task joining backend processes to pg_stat_activity.
+// The backend pids are only meaningful in /proc of the server's host.
+func getBackendOSTasks(db *sql.DB) []CollectionTask {
+	return []CollectionTask{{
+		Category:    "postgresql",
+		Name:        "backend_os_stats",
+		ArchivePath: "postgresql/backend_os_stats.tsv",
+		Collector: func(cfg *Config, w io.Writer) error {
+			if !isLocalHost(cfg.Host) {
+				return NewSkipError(fmt.Sprintf("PostgreSQL host %s is not this machine", cfg.Host))
+			}
+			return collectBackendOSStats(db, "/proc", w)
+		},
+	}}
+}
+
+// collectBackendOSStats reads /proc for every backend in pg_stat_activity.
+// Backends without a visible process (remote server, other PID namespace)
+// are left out; if none are visible the collector is skipped.
+func collectBackendOSStats(db *sql.DB, procRoot string, w io.Writer) error {
+	if db == nil {
+		return fmt.Errorf("PostgreSQL not initialized")
+	}
+
+	rows, err := db.Query(backendActivityQuery)
+	if err != nil {
+		if isPGUnavailableError(err) {
+			return NewSkipError(err.Error())
+		}
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer closeErrCheck(rows, "query rows")
+
+	table := &tsvTable{Columns: append(append([]string{}, backendActivityColumns...), backendOSColumns...)}
+	for rows.Next() {
+		activity := make([]sql.NullString, len(backendActivityColumns))
+		dest := make([]any, len(activity))
+		for i := range activity {
+			dest[i] = &activity[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+
+		stats, ok := readBackendOSStats(filepath.Join(procRoot, activity[0].String))
+		if !ok {
+			continue
+		}
+		row := make([]string, 0, len(table.Columns))
+		for _, v := range activity {
+			row = append(row, v.String)
+		}
+		for _, col := range backendOSColumns {
+			row = append(row, stats[col])
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	if len(table.Rows) == 0 {
+		return NewSkipError("no backend processes visible in /proc (remote server?)")
+	}
+	return table.writeTSV(w)
+}
+
+// readBackendOSStats reads the per-process files of one backend. It reports
+// false if the process does not exist or is not a PostgreSQL process (the
+// pid belongs to another PID namespace); unreadable files leave values unset.
+func readBackendOSStats(dir string) (map[string]string, bool) {
+	if !isPostgresProcess(dir) {
+		return nil, false
+	}
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, false
+	}
+
+	stats := make(map[string]string)
+	parseProcPidStat(string(stat), stats)
+
+	if data, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+		parseProcFields(string(data), backendStatusFields, stats)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "smaps_rollup")); err == nil {
+		parseProcFields(string(data), backendSmapsFields, stats)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "io")); err == nil {
+		parseProcFields(string(data), backendIOFields, stats)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "oom_score")); err == nil {
+		stats["oom_score"] = strings.TrimSpace(string(data))
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "wchan")); err == nil {
+		if wchan := strings.TrimSpace(string(data)); wchan != "0" {
+			stats["wchan"] = wchan
+		}
+	}
+	if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		stats["fd_count"] = strconv.Itoa(len(fds))
+	}
+	return stats, true
+}
+
+// isPostgresProcess reports whether the process of a /proc/<pid> directory
+// runs the postgres executable, by its command name or process title.
+func isPostgresProcess(dir string) bool {
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		if name := strings.TrimSpace(string(comm)); name == "postgres" || name == "postmaster" {
+			return true
+		}
+	}
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	return err == nil && strings.HasPrefix(string(cmdline), "postgres:")
+}
+
+// parseProcPidStat extracts state, fault and CPU time fields from /proc/<pid>/stat.
+// The command name may contain spaces, so fields are counted after its closing ")".
+func parseProcPidStat(content string, stats map[string]string) {
+	end := strings.LastIndex(content, ")")
+	if end < 0 {
+		return
+	}
+	// Fields after the command start at field 3 (state)
+	fields := strings.Fields(content[end+1:])
+	if len(fields) < 13 {
+		return
+	}
+	stats["proc_state"] = fields[0]
+	stats["minflt"] = fields[7]
+	stats["majflt"] = fields[9]
+	for col, i := range map[string]int{"utime_s": 11, "stime_s": 12} {
+		if ticks, err := strconv.ParseFloat(fields[i], 64); err == nil {
+			stats[col] = strconv.FormatFloat(ticks/ClockTicksPerSecond, 'f', 2, 64)
+		}
+	}
+}
+
+// parseProcFields copies "Name: value [kB]" lines into stats under their column names.
+func parseProcFields(content string, columns map[string]string, stats map[string]string) {
+	for _, line := range strings.Split(content, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		col, wanted := columns[name]
+		if !wanted {
+			continue
+		}
+		if fields := strings.Fields(value); len(fields) > 0 {
+			stats[col] = fields[0]
+		}
+	}
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// writeFakeProc creates a /proc/<pid> directory with the files read for a backend
+func writeFakeProc(t *testing.T, root, pid, comm string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	files := map[string]string{
+		"comm":   comm + "\n",
+		"stat":   pid + " (" + comm + ") S 1 4242 4242 0 -1 4194560 1500 0 3 0 250 75 0 0 20 0 1 0",
+		"status": "VmRSS:\t   10240 kB\nThreads:\t1\n",
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCollectBackendOSStats verifies backends are joined to their /proc entries
+// and backends without a visible postgres process are dropped
+func TestCollectBackendOSStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	procRoot := t.TempDir()
+	writeFakeProc(t, procRoot, "4242", "postgres")
+	writeFakeProc(t, procRoot, "4243", "bash") // Same pid in another namespace
+	mock.ExpectQuery("SELECT pid").WillReturnRows(
+		sqlmock.NewRows(backendActivityColumns).
+			AddRow("4242", "client backend", "app", "alice", "active", "IO", "DataFileRead").
+			AddRow("4243", "client backend", "app", "carol", "active", nil, nil).
+			AddRow("2147483646", "client backend", "app", "bob", "idle", nil, nil))
+
+	var buf bytes.Buffer
+	if err := collectBackendOSStats(db, procRoot, &buf); err != nil {
+		t.Fatalf("collectBackendOSStats failed: %v", err)
+	}
+
+	table, err := parseTSV(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parseTSV failed: %v", err)
+	}
+	if len(table.Rows) != 1 {
+		t.Fatalf("expected 1 visible backend, got %d", len(table.Rows))
+	}
+	row := table.Rows[0]
+	if row[table.columnIndex("pid")] != "4242" || row[table.columnIndex("wait_event")] != "DataFileRead" {
+		t.Errorf("unexpected activity columns %q", row)
+	}
+	for col, expected := range map[string]string{"proc_state": "S", "utime_s": "2.50", "vm_rss_kb": "10240", "fd_count": "0"} {
+		if got := row[table.columnIndex(col)]; got != expected {
+			t.Errorf("%s: expected %q, got %q", col, expected, got)
+		}
+	}
+}
+
+// TestCollectBackendOSStatsRemote verifies the collector is skipped when no backend is visible
+func TestCollectBackendOSStatsRemote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	mock.ExpectQuery("SELECT pid").WillReturnRows(
+		sqlmock.NewRows(backendActivityColumns).
+			AddRow("2147483646", "client backend", "app", "bob", "idle", nil, nil))
+
+	var buf bytes.Buffer
+	err = collectBackendOSStats(db, "/proc", &buf)
+	var skipErr SkipError
+	if !errors.As(err, &skipErr) {
+		t.Errorf("expected SkipError, got %v", err)
+	}
+}
+
+// TestParseProcPidStat verifies field positions with a command name containing spaces
+func TestParseProcPidStat(t *testing.T) {
+	stats := make(map[string]string)
+	parseProcPidStat("4242 (postgres: app db) S 1 4242 4242 0 -1 4194560 1500 0 3 0 250 75 0 0 20 0 1 0", stats)
+
+	expected := map[string]string{"proc_state": "S", "minflt": "1500", "majflt": "3", "utime_s": "2.50", "stime_s": "0.75"}
+	for k, v := range expected {
+		if stats[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, stats[k])
+		}
+	}
+}
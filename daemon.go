@@ -0,0 +1,552 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Daemon Defaults
+const (
+	DefaultSpoolDir     = "/var/spool/radar"
+	DefaultEvery        = 15 * time.Minute
+	DefaultKeepArchives = 96
+	DefaultMaxAge       = 7 * 24 * time.Hour
+)
+
+// Journal priority prefixes (see sd-daemon(3)), understood by journald
+// when radar runs as a systemd service with stderr connected to the journal
+const (
+	JournalInfoPrefix  = "<6>"
+	JournalErrorPrefix = "<3>"
+)
+
+// PartialSuffix marks an archive that is still being written
+const PartialSuffix = ".partial"
+
+// DaemonConfig holds scheduling and retention settings for radar daemon
+type DaemonConfig struct {
+	ConfigFile string
+	SpoolDir   string
+	Every      time.Duration
+	Keep       int
+	MaxAge     time.Duration
+	MaxBytes   byteSize
+	Triggers   TriggerThresholds
+}
+
+// byteSize is a flag.Value accepting sizes with an optional K, M, G or T suffix
+type byteSize int64
+
+// String returns the size in bytes.
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+// Set parses a size such as 512M or 10G (binary multiples).
+func (b *byteSize) Set(s string) error {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(strings.TrimSuffix(v, "B"), "I")
+
+	multiplier := int64(1)
+	if v != "" {
+		switch v[len(v)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			v = v[:len(v)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	*b = byteSize(n * multiplier)
+	return nil
+}
+
+// registerDaemonFlags defines the daemon scheduling and retention flags on fs.
+func registerDaemonFlags(fs *flag.FlagSet, dcfg *DaemonConfig) {
+	fs.StringVar(&dcfg.ConfigFile, "config", "", "configuration file (name = value per line, reloaded on SIGHUP)")
+	fs.StringVar(&dcfg.SpoolDir, "spool-dir", DefaultSpoolDir, "directory for collected archives")
+	fs.DurationVar(&dcfg.Every, "every", DefaultEvery, "interval between collections")
+	fs.IntVar(&dcfg.Keep, "keep", DefaultKeepArchives, "maximum number of archives to keep (0 = unlimited)")
+	fs.DurationVar(&dcfg.MaxAge, "max-age", DefaultMaxAge, "remove archives older than this (0 = unlimited)")
+	fs.Var(&dcfg.MaxBytes, "max-bytes", "maximum total size of kept archives, e.g. 5G (0 = unlimited)")
+	registerTriggerFlags(fs, &dcfg.Triggers)
+}
+
+// loadDaemonConfig parses daemon arguments and the optional configuration file.
+// Command-line flags take precedence over settings in the file.
+func loadDaemonConfig(args []string) (*Config, *DaemonConfig, error) {
+	fs := flag.NewFlagSet("radar daemon", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: radar daemon [options]\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	cfg := &Config{}
+	dcfg := &DaemonConfig{}
+	registerConfigFlags(fs, cfg)
+	registerDaemonFlags(fs, dcfg)
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if dcfg.ConfigFile != "" {
+		if err := applyConfigFile(fs, dcfg.ConfigFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfg, err := finishConfig(fs, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dcfg.SpoolDir == "" {
+		return nil, nil, fmt.Errorf("--spool-dir must not be empty")
+	}
+	if dcfg.Every <= 0 {
+		return nil, nil, fmt.Errorf("--every must be positive")
+	}
+	if dcfg.Keep < 0 || dcfg.MaxAge < 0 {
+		return nil, nil, fmt.Errorf("--keep and --max-age must not be negative")
+	}
+	if err := dcfg.Triggers.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, dcfg, nil
+}
+
+// applyConfigFile sets flags from a file of "name = value" lines.
+// Blank lines and lines starting with # are ignored.
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	defer closeErrCheck(f, "config file")
+
+	setOnCommandLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected name = value", path, lineNo)
+		}
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		if name == "config" || fs.Lookup(name) == nil {
+			return fmt.Errorf("%s:%d: unknown setting %q", path, lineNo, name)
+		}
+		if setOnCommandLine[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", path, lineNo, name, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// daemon holds the state of a running radar daemon
+type daemon struct {
+	args []string
+	cfg  *Config
+	dcfg *DaemonConfig
+
+	watch       *time.Ticker // Trigger signal checks, nil when no trigger is set
+	dataDir     string       // Detected data directory, for free space checks
+	lastTrigger time.Time
+}
+
+// runDaemon is the entry point for "radar daemon". It collects an archive
+// every --every into --spool-dir until SIGINT or SIGTERM, reloading its
+// configuration on SIGHUP.
+func runDaemon(args []string) int {
+	infoLog.SetPrefix(JournalInfoPrefix)
+	errorLog.SetPrefix(JournalErrorPrefix + "ERROR: ")
+
+	cfg, dcfg, err := loadDaemonConfig(args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			errorLog.Println(err)
+		}
+		return ExitUsageError
+	}
+
+	d := &daemon{args: args, cfg: cfg, dcfg: dcfg}
+	if err := d.prepareSpool(); err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	d.connect()
+	defer d.disconnect()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	infoLog.Printf("radar daemon started: collecting every %s into %s", d.dcfg.Every, d.dcfg.SpoolDir)
+
+	d.resetWatch()
+	defer d.stopWatch()
+
+	// Start time of the last collection; zero runs the first one at once
+	var lastStarted time.Time
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		// A stop request wins over a due collection, since a collection can
+		// outlast its interval and leave the timer already expired
+		select {
+		case sig := <-stop:
+			infoLog.Printf("Received %s, shutting down", sig)
+			return 0
+		default:
+		}
+
+		select {
+		case <-timer.C:
+			lastStarted = time.Now()
+			d.runOnce(lastStarted, nil)
+			timer.Reset(time.Until(lastStarted.Add(d.dcfg.Every)))
+		case <-d.watchC():
+			d.checkTriggers()
+		case <-hup:
+			d.onHangup(timer, lastStarted)
+		case sig := <-stop:
+			infoLog.Printf("Received %s, shutting down", sig)
+			return 0
+		}
+	}
+}
+
+// onHangup reloads the configuration on SIGHUP and reschedules the pending
+// collection, started by timer, to a changed --every after lastStarted.
+func (d *daemon) onHangup(timer *time.Timer, lastStarted time.Time) {
+	d.reload()
+	d.resetWatch()
+	timer.Reset(time.Until(lastStarted.Add(d.dcfg.Every)))
+}
+
+// prepareSpool creates the spool directory and removes archives left
+// incomplete by an interrupted collection.
+func (d *daemon) prepareSpool() error {
+	if err := os.MkdirAll(d.dcfg.SpoolDir, 0o750); err != nil {
+		return fmt.Errorf("creating spool directory: %w", err)
+	}
+	partials, _ := filepath.Glob(filepath.Join(d.dcfg.SpoolDir, "radar-*.zip"+PartialSuffix))
+	for _, p := range partials {
+		if err := os.Remove(p); err == nil {
+			infoLog.Printf("Removed incomplete archive %s", p)
+		}
+	}
+	return nil
+}
+
+// connect opens the long-lived PostgreSQL connection, reused by every run.
+// Failures are logged and retried before the next collection.
+func (d *daemon) connect() {
+	connectPersistent(d.cfg)
+}
+
+// connectPersistent opens a single PostgreSQL connection that is kept open
+// between collections, unless one is already open or PostgreSQL is skipped.
+func connectPersistent(cfg *Config) {
+	if cfg.SkipPostgres || cfg.DB != nil {
+		return
+	}
+	if err := initPostgreSQL(cfg); err != nil {
+		errorLog.Printf("Could not connect to PostgreSQL: %v", err)
+		return
+	}
+
+	// A single connection kept open between runs
+	cfg.DB.SetMaxOpenConns(1)
+	cfg.DB.SetMaxIdleConns(1)
+	cfg.DB.SetConnMaxLifetime(0)
+	cfg.DB.SetConnMaxIdleTime(0)
+
+	if cfg.Verbose {
+		infoLog.Printf("PostgreSQL connected at %s:%d/%s", cfg.Host, cfg.Port, cfg.Database)
+	}
+}
+
+// disconnect closes the PostgreSQL connection, if open.
+func (d *daemon) disconnect() {
+	if d.cfg.DB != nil {
+		closeErrCheck(d.cfg.DB, "database connection")
+		d.cfg.DB = nil
+	}
+}
+
+// runOnce writes one archive into the spool directory and applies retention.
+// A triggered collection carries the reasons and is limited to the trigger profile.
+func (d *daemon) runOnce(started time.Time, reasons []TriggerReason) {
+	d.connect()
+
+	// Each run works on a copy so per-run state (e.g. detected data
+	// directory) does not leak into the next one
+	runCfg := *d.cfg
+	runCfg.TriggerReasons = reasons
+	if len(reasons) > 0 {
+		d.dcfg.Triggers.applyProfile(&runCfg)
+	}
+	if runCfg.DB == nil {
+		runCfg.SkipPostgres = true
+	}
+
+	name := archiveName(started)
+	if len(reasons) > 0 {
+		name = strings.TrimSuffix(name, ".zip") + "-trigger.zip"
+	}
+	final := filepath.Join(d.dcfg.SpoolDir, name)
+	partial := final + PartialSuffix
+
+	collected, err := writeArchive(&runCfg, partial)
+	if err == nil && collected == 0 {
+		err = fmt.Errorf("no data collected")
+	}
+	if err == nil {
+		err = os.Rename(partial, final)
+	}
+	if err != nil {
+		errorLog.Printf("Collection failed: %v", err)
+		if rmErr := os.Remove(partial); rmErr != nil && !os.IsNotExist(rmErr) {
+			errorLog.Printf("Failed to remove %s: %v", partial, rmErr)
+		}
+		return
+	}
+
+	var sizeKB int64
+	if stat, err := os.Stat(final); err == nil {
+		sizeKB = stat.Size() / 1024
+	}
+	infoLog.Printf("Archive created: %s (%d collectors, %d KB, %s)",
+		final, collected, sizeKB, time.Since(started).Round(time.Millisecond))
+
+	removed, err := enforceRetention(d.dcfg.SpoolDir, d.dcfg.Keep, d.dcfg.MaxAge, int64(d.dcfg.MaxBytes), time.Now())
+	if err != nil {
+		errorLog.Printf("Retention failed: %v", err)
+	}
+	for _, path := range removed {
+		infoLog.Printf("Removed archive %s (retention)", path)
+	}
+}
+
+// resetWatch (re)starts the trigger signal ticker to match the configuration.
+func (d *daemon) resetWatch() {
+	d.stopWatch()
+	if d.dcfg.Triggers.Enabled() {
+		d.watch = time.NewTicker(d.dcfg.Triggers.WatchInterval)
+		if d.cfg.Verbose {
+			infoLog.Printf("Watching trigger signals every %s", d.dcfg.Triggers.WatchInterval)
+		}
+	}
+}
+
+// stopWatch stops the trigger signal ticker, if running.
+func (d *daemon) stopWatch() {
+	if d.watch != nil {
+		d.watch.Stop()
+		d.watch = nil
+	}
+}
+
+// watchC returns the trigger ticker channel, or nil (never ready) when
+// no trigger is configured.
+func (d *daemon) watchC() <-chan time.Time {
+	if d.watch == nil {
+		return nil
+	}
+	return d.watch.C
+}
+
+// checkTriggers measures the watched signals and takes a triggered
+// collection when a threshold is crossed outside the cooldown period.
+func (d *daemon) checkTriggers() {
+	now := time.Now()
+	if !d.lastTrigger.IsZero() && now.Sub(d.lastTrigger) < d.dcfg.Triggers.Cooldown {
+		return
+	}
+
+	d.connect()
+	if d.dataDir == "" {
+		d.dataDir = d.detectDataDir()
+	}
+
+	reasons := d.dcfg.Triggers.evaluate(readTriggerSignals(d.cfg, d.dataDir), now)
+	if len(reasons) == 0 {
+		return
+	}
+
+	descriptions := make([]string, len(reasons))
+	for i, r := range reasons {
+		descriptions[i] = r.String()
+	}
+	infoLog.Printf("Triggered (%s): collecting %s profile", strings.Join(descriptions, ", "), d.dcfg.Triggers.Profile)
+
+	d.lastTrigger = now
+	d.runOnce(now, reasons)
+}
+
+// detectDataDir returns the configured or server-reported data directory,
+// or "" if unknown (e.g. no connection or insufficient privileges).
+func (d *daemon) detectDataDir() string {
+	if d.cfg.DataDir != "" {
+		return d.cfg.DataDir
+	}
+	if d.cfg.DB == nil {
+		return ""
+	}
+	var dataDir string
+	if err := d.cfg.DB.QueryRow("SHOW data_directory").Scan(&dataDir); err != nil {
+		return ""
+	}
+	return dataDir
+}
+
+// reload re-reads arguments and the configuration file. The PostgreSQL
+// connection is kept unless connection settings changed. On error the
+// previous configuration stays in effect.
+func (d *daemon) reload() {
+	cfg, dcfg, err := loadDaemonConfig(d.args)
+	if err != nil {
+		errorLog.Printf("Reload failed, keeping previous configuration: %v", err)
+		return
+	}
+
+	if cfg.SkipPostgres != d.cfg.SkipPostgres ||
+		cfg.ConnectionString(cfg.Database) != d.cfg.ConnectionString(d.cfg.Database) {
+		d.disconnect()
+		d.cfg = cfg
+		d.dataDir = ""
+		d.connect()
+	} else {
+		cfg.DB = d.cfg.DB
+		d.cfg = cfg
+	}
+
+	d.dcfg = dcfg
+	if err := d.prepareSpool(); err != nil {
+		errorLog.Println(err)
+	}
+	infoLog.Printf("Configuration reloaded: collecting every %s into %s", d.dcfg.Every, d.dcfg.SpoolDir)
+}
+
+// spooledArchive is a completed archive found in the spool directory
+type spooledArchive struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// enforceRetention removes archives from dir beyond the newest keep, older
+// than maxAge, or once the newest archives add up to more than maxBytes.
+// Zero disables a limit. The newest archive is never removed.
+// Returns the paths removed.
+func enforceRetention(dir string, keep int, maxAge time.Duration, maxBytes int64, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []spooledArchive
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "radar-") || !strings.HasSuffix(name, ".zip") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, spooledArchive{
+			path:    filepath.Join(dir, name),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		})
+	}
+
+	// Newest first; archive names sort by timestamp as a tie-breaker
+	sort.Slice(archives, func(i, j int) bool {
+		if !archives[i].modTime.Equal(archives[j].modTime) {
+			return archives[i].modTime.After(archives[j].modTime)
+		}
+		return archives[i].path > archives[j].path
+	})
+
+	var removed []string
+	var total int64
+	var firstErr error
+	for i, a := range archives {
+		total += a.size
+		if i == 0 {
+			continue
+		}
+
+		expired := (keep > 0 && i >= keep) ||
+			(maxAge > 0 && now.Sub(a.modTime) > maxAge) ||
+			(maxBytes > 0 && total > maxBytes)
+		if !expired {
+			continue
+		}
+
+		if err := os.Remove(a.path); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		removed = append(removed, a.path)
+	}
+
+	return removed, firstErr
+}
@@ -0,0 +1,204 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestByteSize verifies size parsing with binary suffixes
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		shouldError bool
+	}{
+		{"0", 0, false},
+		{"1024", 1024, false},
+		{"512K", 512 << 10, false},
+		{"100M", 100 << 20, false},
+		{"5G", 5 << 30, false},
+		{"5GiB", 5 << 30, false},
+		{"2tb", 2 << 40, false},
+		{"", 0, true},
+		{"-1G", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var b byteSize
+			err := b.Set(tt.input)
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if int64(b) != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, int64(b))
+			}
+		})
+	}
+}
+
+// TestLoadDaemonConfig verifies config file settings and command-line precedence
+func TestLoadDaemonConfig(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "radar.conf")
+	conf := "# radar daemon settings\n" +
+		"every = 5m\n" +
+		"keep = 10\n" +
+		"max-bytes = 1G\n" +
+		"spool-dir = \"/tmp/from-file\"\n" +
+		"skip-postgres = true\n"
+	if err := os.WriteFile(confPath, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, dcfg, err := loadDaemonConfig([]string{"--config", confPath, "--keep", "3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dcfg.Every != 5*time.Minute {
+		t.Errorf("expected every=5m from file, got %s", dcfg.Every)
+	}
+	if dcfg.Keep != 3 {
+		t.Errorf("expected command-line keep=3 to win, got %d", dcfg.Keep)
+	}
+	if dcfg.MaxBytes != 1<<30 {
+		t.Errorf("expected max-bytes=1G, got %d", dcfg.MaxBytes)
+	}
+	if dcfg.SpoolDir != "/tmp/from-file" {
+		t.Errorf("expected unquoted spool-dir, got %q", dcfg.SpoolDir)
+	}
+	if !cfg.SkipPostgres {
+		t.Error("expected skip-postgres from file")
+	}
+
+	t.Run("unknown setting rejected", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.conf")
+		if err := os.WriteFile(bad, []byte("bogus = 1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := loadDaemonConfig([]string{"--config", bad}); err == nil {
+			t.Error("expected error for unknown setting")
+		}
+	})
+
+	t.Run("non-positive interval rejected", func(t *testing.T) {
+		if _, _, err := loadDaemonConfig([]string{"--skip-postgres", "--every", "0s"}); err == nil {
+			t.Error("expected error for --every 0s")
+		}
+	})
+}
+
+// TestReloadReschedules verifies a SIGHUP that shortens --every moves the
+// pending collection
+func TestReloadReschedules(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "radar.conf")
+	writeConf := func(every string) {
+		conf := "every = " + every + "\nspool-dir = " + dir + "\nskip-postgres = true\n"
+		if err := os.WriteFile(confPath, []byte(conf), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConf("1h")
+	args := []string{"--config", confPath}
+	cfg, dcfg, err := loadDaemonConfig(args)
+	if err != nil {
+		t.Fatalf("loadDaemonConfig failed: %v", err)
+	}
+	d := &daemon{args: args, cfg: cfg, dcfg: dcfg}
+
+	// The last collection started a minute ago; the next one is due in 59m
+	lastStarted := time.Now().Add(-time.Minute)
+	timer := time.NewTimer(time.Until(lastStarted.Add(d.dcfg.Every)))
+	defer timer.Stop()
+
+	writeConf("1m")
+	d.onHangup(timer, lastStarted)
+	if d.dcfg.Every != time.Minute {
+		t.Fatalf("expected every=1m after reload, got %s", d.dcfg.Every)
+	}
+	select {
+	case <-timer.C:
+	case <-time.After(5 * time.Second):
+		t.Error("the next collection was not moved to the new interval")
+	}
+}
+
+// TestEnforceRetention verifies archives are removed by count, age and total size
+func TestEnforceRetention(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Five archives, one hour apart, 100 bytes each (newest first)
+	setup := func(t *testing.T) (string, []string) {
+		dir := t.TempDir()
+		var paths []string
+		for i := 0; i < 5; i++ {
+			ts := now.Add(-time.Duration(i) * time.Hour)
+			path := filepath.Join(dir, "radar-host-"+ts.Format(TimestampFormat)+".zip")
+			if err := os.WriteFile(path, make([]byte, 100), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, ts, ts); err != nil {
+				t.Fatal(err)
+			}
+			paths = append(paths, path)
+		}
+		// Unrelated files are left alone
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return dir, paths
+	}
+
+	tests := []struct {
+		name     string
+		keep     int
+		maxAge   time.Duration
+		maxBytes int64
+		removed  int
+	}{
+		{"no limits", 0, 0, 0, 0},
+		{"keep count", 2, 0, 0, 3},
+		{"max age", 0, 150 * time.Minute, 0, 2},
+		{"max bytes", 0, 0, 250, 3},
+		{"newest always kept", 0, time.Minute, 10, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, paths := setup(t)
+			removed, err := enforceRetention(dir, tt.keep, tt.maxAge, tt.maxBytes, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(removed) != tt.removed {
+				t.Errorf("expected %d removed, got %d: %v", tt.removed, len(removed), removed)
+			}
+			if _, err := os.Stat(paths[0]); err != nil {
+				t.Errorf("newest archive was removed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+				t.Errorf("unrelated file was removed: %v", err)
+			}
+		})
+	}
+}
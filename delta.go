@@ -0,0 +1,361 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateIntervalColumn is appended to every *_rate.tsv row
+const RateIntervalColumn = "interval_seconds"
+
+// SimpleCounterTask defines a source of cumulative counters for --delta
+type SimpleCounterTask struct {
+	Name        string   // Counter source name, used in logs
+	ArchivePath string   // Path of the *_rate.tsv output
+	Keys        []string // Identifying columns a row is matched on
+	Gauges      []string // Numeric columns that are not cumulative
+	Snapshot    func(*Config) (*tsvTable, error)
+}
+
+// pgCounterTask builds a counter source from an instance-level query task.
+// Rates are written next to the raw snapshot as <name>_rate.tsv. It panics
+// at package initialization if name is not a query task.
+func pgCounterTask(name string, keys []string, gauges ...string) SimpleCounterTask {
+	var source SimpleQueryTask
+	for _, t := range postgresQueryTasks {
+		if t.Name == name {
+			source = t
+		}
+	}
+	if source.Query == "" {
+		panic(fmt.Sprintf("counter task %q is not in postgresQueryTasks", name))
+	}
+	return SimpleCounterTask{
+		Name:        name,
+		ArchivePath: rateArchivePath(source.ArchivePath),
+		Keys:        keys,
+		Gauges:      gauges,
+		Snapshot: func(cfg *Config) (*tsvTable, error) {
+			var buf bytes.Buffer
+			if err := pgQueryToTSV(cfg.DB, source.Query, &buf, tsvOptions{Header: true}); err != nil {
+				return nil, err
+			}
+			return parseTSV(buf.Bytes())
+		},
+	}
+}
+
+// rateArchivePath returns the *_rate.tsv path next to a raw snapshot
+func rateArchivePath(rawPath string) string {
+	if i := strings.LastIndex(rawPath, "."); i > strings.LastIndex(rawPath, "/") {
+		rawPath = rawPath[:i]
+	}
+	return rawPath + "_rate.tsv"
+}
+
+// PostgreSQL cumulative statistics views sampled twice with --delta (sorted alphabetically by name)
+var postgresCounterTasks = []SimpleCounterTask{
+	pgCounterTask("bgwriter", nil),
+	pgCounterTask("checkpointer", nil),
+	pgCounterTask("databases_blk", []string{"datname"}),
+	pgCounterTask("databases_tup", []string{"datname"}),
+	pgCounterTask("databases_xact", []string{"datname"}),
+	pgCounterTask("stat_io", []string{"backend_type", "object", "context"}, "op_bytes"),
+	pgCounterTask("stat_slru", []string{"name"}),
+	pgCounterTask("stat_wal", nil),
+}
+
+// getCounterTasks returns the counter sources applicable to this run
+func getCounterTasks(cfg *Config) []SimpleCounterTask {
+	var tasks []SimpleCounterTask
+	if !cfg.SkipSystem {
+		tasks = append(tasks, systemCounterTasks...)
+	}
+	if !cfg.SkipPostgres {
+		tasks = append(tasks, postgresCounterTasks...)
+	}
+	return tasks
+}
+
+// counterSnapshot is the first reading of one counter source
+type counterSnapshot struct {
+	task  SimpleCounterTask
+	table *tsvTable
+	taken time.Time
+	err   error
+}
+
+// deltaRun holds the first snapshots of a --delta collection
+type deltaRun struct {
+	started   time.Time
+	snapshots []counterSnapshot
+}
+
+// startDelta takes the first snapshot of every counter source.
+func startDelta(cfg *Config) *deltaRun {
+	run := &deltaRun{started: time.Now()}
+	for _, task := range getCounterTasks(cfg) {
+		table, err := task.Snapshot(cfg)
+		run.snapshots = append(run.snapshots, counterSnapshot{
+			task:  task,
+			table: table,
+			taken: time.Now(),
+			err:   err,
+		})
+	}
+	if cfg.Verbose {
+		infoLog.Printf("Took first counter snapshot of %d sources for %s delta", len(run.snapshots), cfg.Delta)
+	}
+	return run
+}
+
+// finish waits until the delta interval has passed since the first snapshot,
+// takes the second snapshot and writes per-second rates to the archive.
+func (run *deltaRun) finish(cfg *Config, zipWriter *zip.Writer) int {
+	time.Sleep(time.Until(run.started.Add(cfg.Delta)))
+
+	tasks := make([]CollectionTask, len(run.snapshots))
+	for i, snap := range run.snapshots {
+		before := snap
+		after, err := before.task.Snapshot(cfg)
+		takenAfter := time.Now()
+
+		tasks[i] = CollectionTask{
+			Category:    "delta",
+			Name:        before.task.Name + "_rate",
+			ArchivePath: before.task.ArchivePath,
+			Collector: func(cfg *Config, w io.Writer) error {
+				if before.err != nil {
+					return before.err
+				}
+				if err != nil {
+					return err
+				}
+				rates := computeRates(before.table, after, before.task.Keys, before.task.Gauges, takenAfter.Sub(before.taken))
+				if len(rates.Rows) == 0 {
+					return NewSkipError("no matching counter rows")
+				}
+				return rates.writeTSV(w)
+			},
+		}
+	}
+	return collect(cfg, zipWriter, tasks)
+}
+
+// computeRates derives per-second rates of the cumulative columns of two
+// snapshots. Rows are matched on the key columns; rows present in only one
+// snapshot are dropped. A counter that went backwards (stats reset) or is not
+// numeric in both snapshots yields an empty field.
+func computeRates(before, after *tsvTable, keys, gauges []string, elapsed time.Duration) *tsvTable {
+	skip := make(map[string]bool)
+	for _, c := range append(append([]string{}, keys...), gauges...) {
+		skip[c] = true
+	}
+
+	// Counter columns: numeric (or NULL) in every row of the second snapshot
+	var counters []int
+	for i, col := range after.Columns {
+		if skip[col] || before.columnIndex(col) < 0 {
+			continue
+		}
+		numeric, seen := true, false
+		for _, row := range after.Rows {
+			if i >= len(row) || row[i] == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(row[i], 64); err != nil {
+				numeric = false
+				break
+			}
+			seen = true
+		}
+		if numeric && seen {
+			counters = append(counters, i)
+		}
+	}
+
+	rowKey := func(t *tsvTable, row []string) string {
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			if idx := t.columnIndex(k); idx >= 0 && idx < len(row) {
+				parts[i] = row[idx]
+			}
+		}
+		return strings.Join(parts, "\x00")
+	}
+
+	previous := make(map[string][]string, len(before.Rows))
+	for _, row := range before.Rows {
+		previous[rowKey(before, row)] = row
+	}
+
+	result := &tsvTable{}
+	result.Columns = append(result.Columns, keys...)
+	for _, i := range counters {
+		result.Columns = append(result.Columns, after.Columns[i])
+	}
+	result.Columns = append(result.Columns, RateIntervalColumn)
+
+	seconds := elapsed.Seconds()
+	for _, row := range after.Rows {
+		prev, ok := previous[rowKey(after, row)]
+		if !ok || seconds <= 0 {
+			continue
+		}
+
+		out := make([]string, 0, len(result.Columns))
+		for _, k := range keys {
+			if idx := after.columnIndex(k); idx >= 0 && idx < len(row) {
+				out = append(out, row[idx])
+			} else {
+				out = append(out, "")
+			}
+		}
+		for _, i := range counters {
+			out = append(out, rateField(prev, before.columnIndex(after.Columns[i]), row, i, seconds))
+		}
+		out = append(out, formatRate(seconds))
+		result.Rows = append(result.Rows, out)
+	}
+	return result
+}
+
+// rateField computes one per-second rate, or "" if it cannot be derived.
+func rateField(prev []string, prevIdx int, row []string, idx int, seconds float64) string {
+	if prevIdx >= len(prev) || idx >= len(row) {
+		return ""
+	}
+	a, errA := strconv.ParseFloat(prev[prevIdx], 64)
+	b, errB := strconv.ParseFloat(row[idx], 64)
+	if errA != nil || errB != nil || b < a {
+		return ""
+	}
+	return formatRate((b - a) / seconds)
+}
+
+// formatRate renders a rate rounded to three decimals without trailing zeros.
+func formatRate(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// diskstatsColumns names the /proc/diskstats fields after the device name.
+// Older kernels report fewer fields; missing ones are left empty.
+var diskstatsColumns = []string{
+	"device", "reads_completed", "reads_merged", "sectors_read", "read_ms",
+	"writes_completed", "writes_merged", "sectors_written", "write_ms",
+	"ios_in_progress", "io_ms", "weighted_io_ms",
+	"discards_completed", "discards_merged", "sectors_discarded", "discard_ms",
+	"flushes_completed", "flush_ms",
+}
+
+// parseDiskstats converts /proc/diskstats into a table keyed on device.
+func parseDiskstats(content string) *tsvTable {
+	t := &tsvTable{Columns: diskstatsColumns}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		row := make([]string, len(diskstatsColumns))
+		copy(row, fields[2:])
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// vmstatCounters are the nr_* entries of /proc/vmstat that are cumulative;
+// all other nr_* entries are gauges
+var vmstatCounters = map[string]bool{"nr_dirtied": true, "nr_written": true}
+
+// parseVMStat converts the cumulative counters of /proc/vmstat into a table
+// keyed on name.
+func parseVMStat(content string) *tsvTable {
+	t := &tsvTable{Columns: []string{"name", "value"}}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "nr_") && !vmstatCounters[fields[0]] {
+			continue
+		}
+		t.Rows = append(t.Rows, []string{fields[0], fields[1]})
+	}
+	return t
+}
+
+// netstatGauges are /proc/net/snmp and /proc/net/netstat entries that are
+// settings or current values rather than counters
+var netstatGauges = map[string]bool{
+	"Ip.Forwarding":    true,
+	"Ip.DefaultTTL":    true,
+	"Tcp.RtoAlgorithm": true,
+	"Tcp.RtoMin":       true,
+	"Tcp.RtoMax":       true,
+	"Tcp.MaxConn":      true,
+	"Tcp.CurrEstab":    true,
+}
+
+// parseNetstat converts the header/value line pairs of /proc/net/snmp and
+// /proc/net/netstat (the data behind "netstat -s") into a table keyed on
+// protocol and name.
+func parseNetstat(contents ...string) *tsvTable {
+	t := &tsvTable{Columns: []string{"protocol", "name", "value"}}
+	for _, content := range contents {
+		lines := strings.Split(content, "\n")
+		for i := 0; i+1 < len(lines); i += 2 {
+			names := strings.Fields(lines[i])
+			values := strings.Fields(lines[i+1])
+			if len(names) < 2 || len(names) != len(values) || names[0] != values[0] {
+				continue
+			}
+			protocol := strings.TrimSuffix(names[0], ":")
+			for j := 1; j < len(names); j++ {
+				if netstatGauges[protocol+"."+names[j]] {
+					continue
+				}
+				t.Rows = append(t.Rows, []string{protocol, names[j], values[j]})
+			}
+		}
+	}
+	return t
+}
+
+// procCounterSnapshot reads files under /proc and parses them into a table.
+func procCounterSnapshot(parse func(contents ...string) *tsvTable, paths ...string) func(*Config) (*tsvTable, error) {
+	return func(*Config) (*tsvTable, error) {
+		contents := make([]string, 0, len(paths))
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil, NewSkipError(fmt.Sprintf("%s not found", path))
+				}
+				return nil, err
+			}
+			contents = append(contents, string(data))
+		}
+		t := parse(contents...)
+		if len(t.Rows) == 0 {
+			return nil, NewSkipError(fmt.Sprintf("no counters in %s", strings.Join(paths, ", ")))
+		}
+		return t, nil
+	}
+}
@@ -0,0 +1,117 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestParseTSVRoundTrip verifies quoted fields survive parsing and rewriting
+func TestParseTSVRoundTrip(t *testing.T) {
+	input := "name\tquery\tcalls\n" +
+		"a\t\"SELECT 1\n  FROM t\"\t10\n" +
+		"b\t\"say \"\"hi\"\"\"\t\n"
+
+	table, err := parseTSV([]byte(input))
+	if err != nil {
+		t.Fatalf("parseTSV failed: %v", err)
+	}
+	if !reflect.DeepEqual(table.Columns, []string{"name", "query", "calls"}) {
+		t.Errorf("unexpected columns %q", table.Columns)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(table.Rows))
+	}
+	if table.Rows[0][1] != "SELECT 1\n  FROM t" || table.Rows[1][1] != `say "hi"` || table.Rows[1][2] != "" {
+		t.Errorf("unexpected rows %q", table.Rows)
+	}
+
+	var buf bytes.Buffer
+	if err := table.writeTSV(&buf); err != nil {
+		t.Fatalf("writeTSV failed: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("round trip mismatch\nexpected: %q\ngot:      %q", input, buf.String())
+	}
+}
+
+// TestComputeRates verifies key matching, gauges, resets and non-numeric columns
+func TestComputeRates(t *testing.T) {
+	before := &tsvTable{
+		Columns: []string{"datname", "xact_commit", "numbackends", "stats_reset"},
+		Rows: [][]string{
+			{"app", "100", "5", "2026-01-01"},
+			{"gone", "10", "1", "2026-01-01"},
+			{"reset", "5000", "1", "2026-01-01"},
+		},
+	}
+	after := &tsvTable{
+		Columns: []string{"datname", "xact_commit", "numbackends", "stats_reset"},
+		Rows: [][]string{
+			{"app", "300", "7", "2026-01-01"},
+			{"new", "50", "1", "2026-01-01"},
+			{"reset", "20", "1", "2026-01-02"},
+		},
+	}
+
+	got := computeRates(before, after, []string{"datname"}, []string{"numbackends"}, 4*time.Second)
+
+	expected := &tsvTable{
+		Columns: []string{"datname", "xact_commit", "interval_seconds"},
+		Rows: [][]string{
+			{"app", "50", "4"},
+			{"reset", "", "4"},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rates\nexpected: %q\ngot:      %q", expected, got)
+	}
+
+	// A key column missing from the snapshots is left empty
+	before.Rows, after.Rows = before.Rows[:1], after.Rows[:1]
+	before.Columns[0], after.Columns[0] = "dbname", "dbname"
+	got = computeRates(before, after, []string{"datname"}, nil, 4*time.Second)
+	if len(got.Rows) == 0 || got.Rows[0][0] != "" {
+		t.Errorf("expected an empty key field, got %q", got.Rows)
+	}
+}
+
+// TestRateArchivePath verifies rate output is placed next to the raw snapshot
+func TestRateArchivePath(t *testing.T) {
+	if got := rateArchivePath("postgresql/stat_io.tsv"); got != "postgresql/stat_io_rate.tsv" {
+		t.Errorf("unexpected path %q", got)
+	}
+}
+
+// TestProcCounterParsers verifies the /proc counter parsers
+func TestProcCounterParsers(t *testing.T) {
+	disk := parseDiskstats("   8       0 sda 100 2 3000 40 50 6 700 80 1 90 120\n")
+	if len(disk.Rows) != 1 || disk.Rows[0][0] != "sda" || disk.Rows[0][1] != "100" || disk.Rows[0][12] != "" {
+		t.Errorf("unexpected diskstats rows %q", disk.Rows)
+	}
+
+	vm := parseVMStat("nr_free_pages 1000\nnr_dirtied 50\npgfault 12345\n")
+	expectedVM := [][]string{{"nr_dirtied", "50"}, {"pgfault", "12345"}}
+	if !reflect.DeepEqual(vm.Rows, expectedVM) {
+		t.Errorf("unexpected vmstat rows %q", vm.Rows)
+	}
+
+	snmp := "Tcp: RtoAlgorithm ActiveOpens CurrEstab\nTcp: 1 42 3\n"
+	netstat := "TcpExt: SyncookiesSent\nTcpExt: 7\n"
+	ns := parseNetstat(snmp, netstat)
+	expectedNS := [][]string{{"Tcp", "ActiveOpens", "42"}, {"TcpExt", "SyncookiesSent", "7"}}
+	if !reflect.DeepEqual(ns.Rows, expectedNS) {
+		t.Errorf("unexpected netstat rows %q", ns.Rows)
+	}
+}
@@ -0,0 +1,608 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Kinds of item changes between two archives
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Unified diff limits
+const (
+	DiffContextLines = 3
+	DiffMaxCells     = 4000000 // Largest LCS table before falling back to a full replacement
+)
+
+// DiffChange is one item added, removed or changed between two archives
+type DiffChange struct {
+	Key    string `json:"key"`
+	Change string `json:"change"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// DiffSection holds the differences found in one archive entry
+type DiffSection struct {
+	Title   string       `json:"title"`
+	Path    string       `json:"path"`
+	Changes []DiffChange `json:"changes,omitempty"`
+	Unified string       `json:"unified,omitempty"`
+}
+
+// ArchiveDiff is the result of comparing two archives
+type ArchiveDiff struct {
+	Before   string        `json:"before"`
+	After    string        `json:"after"`
+	Sections []DiffSection `json:"sections"`
+}
+
+// SimpleDiffer compares the archive entries matching a pattern.
+// Entries are parsed into key/value items by Items; if Items is nil,
+// they are compared as text and produce a unified diff.
+type SimpleDiffer struct {
+	Title   string
+	Pattern string // path.Match pattern of archive entries
+	Items   func(data []byte) map[string]string
+}
+
+// Archive differs (grouped by subject)
+var archiveDiffers = []SimpleDiffer{
+	// PostgreSQL
+	{Title: "PostgreSQL version", Pattern: "postgresql/version.tsv", Items: firstRowItems},
+	{Title: "PostgreSQL settings", Pattern: "postgresql/configuration.tsv", Items: tsvItems([]string{"name"}, "setting", "unit")},
+	{Title: "Configuration file settings", Pattern: "postgresql/file_settings.tsv", Items: tsvItems([]string{"name"}, "setting", "sourcefile")},
+	{Title: "HBA rules", Pattern: "postgresql/pg_hba_file_rules.tsv", Items: tsvItems([]string{"type", "database", "user_name", "address", "netmask", "auth_method"}, "options")},
+	{Title: "Configuration file", Pattern: "postgresql/*.conf"},
+
+	// Database objects
+	{Title: "Extensions", Pattern: "databases/*/extensions.tsv", Items: tsvItems([]string{"extname"}, "extversion")},
+	{Title: "Tables", Pattern: "databases/*/tables.tsv", Items: tsvItems([]string{"schemaname", "tablename"})},
+	{Title: "Indexes", Pattern: "databases/*/indexes.tsv", Items: tsvItems([]string{"schemaname", "indexname"}, "indexdef")},
+
+	// Operating system
+	{Title: "Kernel", Pattern: "system/uname.out", Items: unameItems},
+	{Title: "Operating system", Pattern: "system/os_release.out", Items: osReleaseItems},
+	{Title: "Operating system", Pattern: "system/system_release.out"},
+	{Title: "Operating system", Pattern: "system/system_version.plist"},
+	{Title: "Kernel parameters", Pattern: "system/sysctl*.out", Items: sysctlItems},
+	{Title: "Kernel parameters", Pattern: "system/sysctl.conf"},
+
+	// Packages
+	{Title: "Packages", Pattern: "system/packages-apt-list-installed.out", Items: aptItems},
+	{Title: "Packages", Pattern: "system/packages-dnf-list-installed.out", Items: dnfItems},
+	{Title: "Packages", Pattern: "system/packages-dpkg.out", Items: dpkgItems},
+	{Title: "Packages", Pattern: "system/packages-rpm.out", Items: rpmItems},
+	{Title: "Packages", Pattern: "system/packages-yum-list-installed.out", Items: dnfItems},
+	{Title: "Packages", Pattern: "system/packages_brew*.out", Items: brewItems},
+
+	// System configuration files
+	{Title: "Configuration file", Pattern: "system/fstab.out"},
+	{Title: "Configuration file", Pattern: "system/hosts.out"},
+	{Title: "Configuration file", Pattern: "system/limits.out"},
+	{Title: "Configuration file", Pattern: "system/locale_conf.out"},
+	{Title: "Configuration file", Pattern: "system/resolv_conf.out"},
+	{Title: "cgroup limit", Pattern: "system/cgroup/*_max.out"},
+}
+
+// sysctlVolatilePrefixes are kernel parameters that change on their own
+var sysctlVolatilePrefixes = []string{
+	"fs.dentry-state", "fs.file-nr", "fs.inode-nr", "fs.inode-state", "fs.quota.",
+	"kernel.ns_last_pid", "kernel.pty.nr", "kernel.random.",
+	"net.netfilter.nf_conntrack_count",
+	"kern.boottime", "kern.sleeptime", "kern.waketime", "vm.loadavg", "vm.swapusage",
+}
+
+// runDiff implements the "radar diff" subcommand.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("radar diff", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: radar diff [options] <before.zip> <after.zip>\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	archives, err := parseArchiveArgs(fs, args, 2)
+	if err == nil && *format != "text" && *format != "json" {
+		err = fmt.Errorf("invalid --format %q: must be text or json", *format)
+	}
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		errorLog.Println(err)
+		fs.Usage()
+		return ExitUsageError
+	}
+
+	before, err := loadArchive(archives[0])
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	after, err := loadArchive(archives[1])
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+
+	d := diffArchives(before, after)
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(d)
+	} else {
+		err = writeDiffText(d, os.Stdout)
+	}
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	return 0
+}
+
+// diffArchives compares every entry covered by a differ, and lists the
+// entries present in only one of the archives.
+func diffArchives(before, after *radarArchive) *ArchiveDiff {
+	d := &ArchiveDiff{Before: before.Path, After: after.Path, Sections: []DiffSection{}}
+
+	for _, differ := range archiveDiffers {
+		for _, name := range unionStrings(before.glob(differ.Pattern), after.glob(differ.Pattern)) {
+			beforeData, inBefore := before.Files[name]
+			afterData, inAfter := after.Files[name]
+			if !inBefore || !inAfter {
+				// Reported with the archive entries below
+				continue
+			}
+			section := DiffSection{Title: differ.Title, Path: name}
+			if differ.Items == nil {
+				section.Unified = unifiedDiff(before.Path+"/"+name, after.Path+"/"+name, string(beforeData), string(afterData))
+			} else {
+				section.Changes = diffItems(differ.Items(beforeData), differ.Items(afterData))
+			}
+			if len(section.Changes) > 0 || section.Unified != "" {
+				d.Sections = append(d.Sections, section)
+			}
+		}
+	}
+
+	if changes := diffItems(entriesOf(before), entriesOf(after)); len(changes) > 0 {
+		d.Sections = append(d.Sections, DiffSection{Title: "Archive entries", Changes: changes})
+	}
+	return d
+}
+
+// entriesOf returns the entry names of an archive as presence-only items.
+func entriesOf(a *radarArchive) map[string]string {
+	items := make(map[string]string, len(a.Names))
+	for _, name := range a.Names {
+		items[name] = ""
+	}
+	return items
+}
+
+// unionStrings returns the sorted union of two string lists.
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// diffItems compares two sets of key/value items, sorted by key.
+func diffItems(before, after map[string]string) []DiffChange {
+	var changes []DiffChange
+	for key, b := range before {
+		a, ok := after[key]
+		switch {
+		case !ok:
+			changes = append(changes, DiffChange{Key: key, Change: ChangeRemoved, Before: b})
+		case a != b:
+			changes = append(changes, DiffChange{Key: key, Change: ChangeChanged, Before: b, After: a})
+		}
+	}
+	for key, a := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, DiffChange{Key: key, Change: ChangeAdded, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// writeDiffText writes the human-readable archive comparison.
+func writeDiffText(d *ArchiveDiff, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", d.Before, d.After); err != nil {
+		return err
+	}
+	if len(d.Sections) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo differences")
+		return err
+	}
+	for _, s := range d.Sections {
+		header := s.Title
+		if s.Path != "" {
+			header += " (" + s.Path + ")"
+		}
+		if _, err := fmt.Fprintf(w, "\n%s\n", header); err != nil {
+			return err
+		}
+		for _, c := range s.Changes {
+			var line string
+			switch c.Change {
+			case ChangeAdded:
+				line = "  + " + c.Key + itemSuffix(c.After)
+			case ChangeRemoved:
+				line = "  - " + c.Key + itemSuffix(c.Before)
+			default:
+				line = fmt.Sprintf("  ~ %s: %s -> %s", c.Key, c.Before, c.After)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, s.Unified); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemSuffix formats an item value after its key, if it has one.
+func itemSuffix(value string) string {
+	if value == "" {
+		return ""
+	}
+	return ": " + value
+}
+
+// tsvItems returns an item parser keyed on the given TSV columns. Item
+// values join the value columns; rows with a repeated key keep the last value.
+func tsvItems(keys []string, values ...string) func([]byte) map[string]string {
+	return func(data []byte) map[string]string {
+		t, err := parseTSV(data)
+		if err != nil {
+			return nil
+		}
+		var keyCols, valueCols []int
+		for _, k := range keys {
+			i := t.columnIndex(k)
+			if i < 0 {
+				return nil
+			}
+			keyCols = append(keyCols, i)
+		}
+		for _, v := range values {
+			if i := t.columnIndex(v); i >= 0 {
+				valueCols = append(valueCols, i)
+			}
+		}
+
+		items := make(map[string]string, len(t.Rows))
+		for _, row := range t.Rows {
+			items[joinColumns(row, keyCols, ".")] = joinColumns(row, valueCols, " ")
+		}
+		return items
+	}
+}
+
+// joinColumns joins the non-empty values of the given columns of a row.
+func joinColumns(row []string, cols []int, sep string) string {
+	var parts []string
+	for _, i := range cols {
+		if row[i] != "" {
+			parts = append(parts, row[i])
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// firstRowItems returns each column of a TSV entry's first row.
+func firstRowItems(data []byte) map[string]string {
+	t, err := parseTSV(data)
+	if err != nil || len(t.Rows) == 0 {
+		return nil
+	}
+	items := make(map[string]string, len(t.Columns))
+	for i, col := range t.Columns {
+		items[col] = t.Rows[0][i]
+	}
+	return items
+}
+
+// unameItems splits "uname -a" output into its kernel fields.
+func unameItems(data []byte) map[string]string {
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return nil
+	}
+	items := map[string]string{
+		"kernel_name":    fields[0],
+		"nodename":       fields[1],
+		"kernel_release": fields[2],
+	}
+	if len(fields) > 3 {
+		items["kernel_version"] = strings.Join(fields[3:], " ")
+	}
+	return items
+}
+
+// osReleaseItems parses /etc/os-release KEY=value lines.
+func osReleaseItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		items[key] = strings.Trim(value, `"'`)
+	}
+	return items
+}
+
+// sysctlItems parses "sysctl -a" output, skipping counters that change on their own.
+// Linux separates names and values with " = ", macOS with ": ".
+func sysctlItems(data []byte) map[string]string {
+	items := make(map[string]string)
+lines:
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			key, value, ok = strings.Cut(line, ": ")
+		}
+		if !ok {
+			continue
+		}
+		for _, prefix := range sysctlVolatilePrefixes {
+			if strings.HasPrefix(key, prefix) {
+				continue lines
+			}
+		}
+		items[strings.TrimSpace(key)] = strings.Join(strings.Fields(value), " ")
+	}
+	return items
+}
+
+// aptItems parses "apt list --installed" lines: name/suite,now version arch [installed].
+func aptItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		name, _, ok := strings.Cut(line, "/")
+		if !ok || len(fields) < 2 {
+			continue
+		}
+		items[name] = fields[1]
+	}
+	return items
+}
+
+// dnfItems parses "dnf/yum list installed" lines: name.arch version repo.
+func dnfItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ".") {
+			continue
+		}
+		items[fields[0]] = fields[1]
+	}
+	return items
+}
+
+// dpkgItems parses "dpkg -l" package lines: status name version arch description.
+func dpkgItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || len(fields[0]) > 3 || strings.ContainsAny(fields[0], "|+/") {
+			continue
+		}
+		items[fields[1]] = fields[0] + " " + fields[2]
+	}
+	return items
+}
+
+// rpmItems parses "rpm -qa" name-version-release.arch lines.
+func rpmItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		release := strings.LastIndex(line, "-")
+		if release <= 0 {
+			continue
+		}
+		version := strings.LastIndex(line[:release], "-")
+		if version <= 0 {
+			continue
+		}
+		items[line[:version]] = line[version+1:]
+	}
+	return items
+}
+
+// brewItems parses "brew list --versions" lines: name version...
+func brewItems(data []byte) map[string]string {
+	items := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		items[fields[0]] = strings.Join(fields[1:], " ")
+	}
+	return items
+}
+
+// diffLine is one line of a line-by-line comparison
+type diffLine struct {
+	op   byte // ' ' (unchanged), '-' (removed) or '+' (added)
+	text string
+}
+
+// unifiedDiff returns a unified diff of two texts, or "" if they are equal.
+func unifiedDiff(beforeName, afterName, before, after string) string {
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	// Hunks are line ranges around changes, merged when their context overlaps
+	var hunks [][2]int
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start, end := max(i-DiffContextLines, 0), min(i+1+DiffContextLines, len(lines))
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	// Line numbers consumed from each side before every diff line
+	beforePos, afterPos := make([]int, len(lines)), make([]int, len(lines))
+	b, a := 0, 0
+	for i, l := range lines {
+		beforePos[i], afterPos[i] = b, a
+		if l.op != '+' {
+			b++
+		}
+		if l.op != '-' {
+			a++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", beforeName, afterName)
+	for _, h := range hunks {
+		var beforeCount, afterCount int
+		for _, l := range lines[h[0]:h[1]] {
+			if l.op != '+' {
+				beforeCount++
+			}
+			if l.op != '-' {
+				afterCount++
+			}
+		}
+		beforeStart, afterStart := beforePos[h[0]], afterPos[h[0]]
+		if beforeCount > 0 {
+			beforeStart++
+		}
+		if afterCount > 0 {
+			afterStart++
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", beforeStart, beforeCount, afterStart, afterCount)
+		for _, l := range lines[h[0]:h[1]] {
+			sb.WriteByte(l.op)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines compares two line lists using a longest common subsequence.
+// Common leading and trailing lines are matched first to keep the table small.
+func diffLines(before, after []string) []diffLine {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, s := range before[:prefix] {
+		lines = append(lines, diffLine{' ', s})
+	}
+	b, a := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	if len(b)*len(a) > DiffMaxCells {
+		for _, s := range b {
+			lines = append(lines, diffLine{'-', s})
+		}
+		for _, s := range a {
+			lines = append(lines, diffLine{'+', s})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of b[i:] and a[j:]
+		lcs := make([][]int, len(b)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(a)+1)
+		}
+		for i := len(b) - 1; i >= 0; i-- {
+			for j := len(a) - 1; j >= 0; j-- {
+				if b[i] == a[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(b) && j < len(a) {
+			switch {
+			case b[i] == a[j]:
+				lines = append(lines, diffLine{' ', b[i]})
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lines = append(lines, diffLine{'-', b[i]})
+				i++
+			default:
+				lines = append(lines, diffLine{'+', a[j]})
+				j++
+			}
+		}
+		for ; i < len(b); i++ {
+			lines = append(lines, diffLine{'-', b[i]})
+		}
+		for ; j < len(a); j++ {
+			lines = append(lines, diffLine{'+', a[j]})
+		}
+	}
+
+	for _, s := range before[len(before)-suffix:] {
+		lines = append(lines, diffLine{' ', s})
+	}
+	return lines
+}
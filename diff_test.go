@@ -0,0 +1,141 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDiffArchives verifies TSV-aware comparisons and archive entry changes
+func TestDiffArchives(t *testing.T) {
+	before := testArchive(map[string]string{
+		"postgresql/configuration.tsv":           "name\tsetting\tunit\tcategory\tshort_desc\nshared_buffers\t16384\t8kB\tMem\tx\nwork_mem\t4096\tkB\tMem\tx\n",
+		"databases/app/extensions.tsv":           "oid\textname\textversion\n1\tplpgsql\t1.0\n2\tpg_stat_statements\t1.10\n",
+		"databases/app/tables.tsv":               "schemaname\ttablename\ttableowner\npublic\torders\tapp\npublic\told\tapp\n",
+		"system/uname.out":                       "Linux db1 6.1.0-18-amd64 #1 SMP Debian x86_64 GNU/Linux\n",
+		"system/sysctl.out":                      "vm.swappiness = 60\nfs.file-nr = 1 0 9\n",
+		"system/packages-rpm.out":                "postgresql16-server-16.2-1PGDG.rhel9.x86_64\n",
+		"postgresql/pg_hba.conf":                 "local all all peer\nhost all all 0.0.0.0/0 md5\n",
+		"postgresql/pg_stat_activity.tsv":        "pid\n1\n",
+		"postgresql/running_activity_maxage.tsv": "pid\n1\n",
+	})
+	after := testArchive(map[string]string{
+		"postgresql/configuration.tsv":    "name\tsetting\tunit\tcategory\tshort_desc\nshared_buffers\t32768\t8kB\tMem\tx\nwork_mem\t4096\tkB\tMem\tx\nhuge_pages\ton\t\tMem\tx\n",
+		"databases/app/extensions.tsv":    "oid\textname\textversion\n1\tplpgsql\t1.0\n2\tpg_stat_statements\t1.11\n",
+		"databases/app/tables.tsv":        "schemaname\ttablename\ttableowner\npublic\torders\tapp\n",
+		"system/uname.out":                "Linux db1 6.1.0-21-amd64 #1 SMP Debian x86_64 GNU/Linux\n",
+		"system/sysctl.out":               "vm.swappiness = 10\nfs.file-nr = 2 0 9\n",
+		"system/packages-rpm.out":         "postgresql16-server-16.3-1PGDG.rhel9.x86_64\n",
+		"postgresql/pg_hba.conf":          "local all all peer\nhost all all 10.0.0.0/8 scram-sha-256\n",
+		"postgresql/pg_stat_activity.tsv": "pid\n2\n",
+		"postgresql/wal_position.tsv":     "current_wal_lsn\n0/1\n",
+	})
+
+	sections := make(map[string]DiffSection)
+	for _, s := range diffArchives(before, after).Sections {
+		sections[s.Path] = s
+	}
+
+	expected := map[string][]DiffChange{
+		"postgresql/configuration.tsv": {
+			{Key: "huge_pages", Change: ChangeAdded, After: "on"},
+			{Key: "shared_buffers", Change: ChangeChanged, Before: "16384 8kB", After: "32768 8kB"},
+		},
+		"databases/app/extensions.tsv": {{Key: "pg_stat_statements", Change: ChangeChanged, Before: "1.10", After: "1.11"}},
+		"databases/app/tables.tsv":     {{Key: "public.old", Change: ChangeRemoved}},
+		"system/uname.out":             {{Key: "kernel_release", Change: ChangeChanged, Before: "6.1.0-18-amd64", After: "6.1.0-21-amd64"}},
+		"system/sysctl.out":            {{Key: "vm.swappiness", Change: ChangeChanged, Before: "60", After: "10"}},
+		"system/packages-rpm.out":      {{Key: "postgresql16-server", Change: ChangeChanged, Before: "16.2-1PGDG.rhel9.x86_64", After: "16.3-1PGDG.rhel9.x86_64"}},
+		"": {
+			{Key: "postgresql/running_activity_maxage.tsv", Change: ChangeRemoved},
+			{Key: "postgresql/wal_position.tsv", Change: ChangeAdded},
+		},
+	}
+	for p, changes := range expected {
+		s, ok := sections[p]
+		if !ok {
+			t.Errorf("%q: no section", p)
+			continue
+		}
+		if len(s.Changes) != len(changes) {
+			t.Errorf("%q: expected %+v, got %+v", p, changes, s.Changes)
+			continue
+		}
+		for i := range changes {
+			if s.Changes[i] != changes[i] {
+				t.Errorf("%q: expected %+v, got %+v", p, changes[i], s.Changes[i])
+			}
+		}
+	}
+
+	if _, ok := sections["postgresql/pg_stat_activity.tsv"]; ok {
+		t.Error("volatile TSV without a differ should not be compared")
+	}
+	hba := sections["postgresql/pg_hba.conf"].Unified
+	if !strings.Contains(hba, "-host all all 0.0.0.0/0 md5\n+host all all 10.0.0.0/8 scram-sha-256\n") {
+		t.Errorf("unexpected pg_hba.conf diff:\n%s", hba)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDiffText(diffArchives(before, after), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "  ~ shared_buffers: 16384 8kB -> 32768 8kB\n") {
+		t.Errorf("unexpected text output:\n%s", buf.String())
+	}
+}
+
+// TestUnifiedDiff verifies hunk headers, context and equal inputs
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\ni\nj\nk\n"
+
+	got := unifiedDiff("before", "after", before, after)
+	expected := "--- before\n+++ after\n" +
+		"@@ -1,10 +1,11 @@\n a\n b\n c\n-d\n+D\n e\n f\n g\n h\n i\n j\n+k\n"
+	if got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	got = unifiedDiff("before", "after", "a\n", "")
+	if got != "--- before\n+++ after\n@@ -1,1 +0,0 @@\n-a\n" {
+		t.Errorf("unexpected removal diff:\n%s", got)
+	}
+
+	if got := unifiedDiff("before", "after", before, before); got != "" {
+		t.Errorf("expected no diff for equal texts, got:\n%s", got)
+	}
+}
+
+// TestPackageItems verifies the package list parsers
+func TestPackageItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) map[string]string
+		input string
+		key   string
+		value string
+	}{
+		{"apt", aptItems, "Listing...\npostgresql-16/jammy-pgdg,now 16.2-1.pgdg22.04+1 amd64 [installed]\n", "postgresql-16", "16.2-1.pgdg22.04+1"},
+		{"dnf", dnfItems, "Installed Packages\npostgresql16-server.x86_64   16.2-1PGDG.rhel9   @pgdg16\n", "postgresql16-server.x86_64", "16.2-1PGDG.rhel9"},
+		{"dpkg", dpkgItems, "||/ Name Version Architecture Description\n+++-====-====-====-====\nii  postgresql-16  16.2-1  amd64  object-relational SQL database\n", "postgresql-16", "ii 16.2-1"},
+		{"rpm", rpmItems, "postgresql16-libs-16.2-1PGDG.rhel9.x86_64\n", "postgresql16-libs", "16.2-1PGDG.rhel9.x86_64"},
+		{"brew", brewItems, "postgresql@16 16.2_1\n", "postgresql@16", "16.2_1"},
+	}
+	for _, tt := range tests {
+		items := tt.parse([]byte(tt.input))
+		if len(items) != 1 || items[tt.key] != tt.value {
+			t.Errorf("%s: expected %s=%s, got %v", tt.name, tt.key, tt.value, items)
+		}
+	}
+}
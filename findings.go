@@ -0,0 +1,584 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Findings output paths at the archive root
+const (
+	FindingsJSONPath = "findings.json"
+	FindingsTextPath = "findings.txt"
+)
+
+// Finding severities, most severe first
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// severityRank orders findings by severity
+var severityRank = map[string]int{SeverityCritical: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// Finding is one problem detected by a health rule
+type Finding struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Title       string `json:"title"`
+	Evidence    string `json:"evidence"`
+	Remediation string `json:"remediation"`
+}
+
+// FindingsReport is the content of findings.json
+type FindingsReport struct {
+	Archive   string    `json:"archive,omitempty"`
+	Generated time.Time `json:"generated"`
+	Findings  []Finding `json:"findings"`
+}
+
+// HealthRule evaluates archive contents and returns its findings
+type HealthRule struct {
+	Name   string
+	Inputs []string // Archive paths the rule reads
+	Check  func(*radarArchive) []Finding
+}
+
+// Health rules (sorted alphabetically by name)
+var healthRules = []HealthRule{
+	{
+		Name:   "cgroup_memory_max",
+		Inputs: []string{"system/cgroup/memory_max.out", "postgresql/configuration.tsv"},
+		Check:  checkCgroupMemoryMax,
+	},
+	{
+		Name:   "checksum_failures",
+		Inputs: []string{"postgresql/databases_checksums.tsv"},
+		Check:  checkChecksumFailures,
+	},
+	{
+		Name:   "inactive_replication_slots",
+		Inputs: []string{"postgresql/replication_slots.tsv", "postgresql/wal_position.tsv"},
+		Check:  checkInactiveSlots,
+	},
+	{
+		Name:   "nvme_io_scheduler",
+		Inputs: []string{"system/io_schedulers.out"},
+		Check:  checkNVMeScheduler,
+	},
+	{
+		Name:   "shared_buffers_memory",
+		Inputs: []string{"system/proc/meminfo.out", "postgresql/configuration.tsv"},
+		Check:  checkSharedBuffers,
+	},
+	{
+		Name:   "sysctl_vm",
+		Inputs: []string{"system/sysctl.out"},
+		Check:  checkSysctlVM,
+	},
+	{
+		Name:   "transparent_hugepage",
+		Inputs: []string{"system/sys/kernel_mm_transparent_hugepage.out"},
+		Check:  checkTransparentHugepage,
+	},
+	{
+		Name:   "xid_wraparound",
+		Inputs: []string{"postgresql/wraparound.tsv"},
+		Check:  checkWraparound,
+	},
+}
+
+// Rule thresholds
+const (
+	SharedBuffersMaxPct       = 40 // Of MemTotal
+	SharedBuffersMinPct       = 10 // Of MemTotal, on hosts with at least SharedBuffersMinHostRAM
+	SharedBuffersMinHostRAM   = 4 << 30
+	InactiveSlotWarnBytes     = 1 << 30
+	SwappinessMax             = 10
+	CgroupSharedBuffersMargin = 2  // memory.max should leave room for more than shared_buffers
+	WraparoundWarnPct         = 50 // Of the 2^31 transaction ID (or multixact) limit
+	WraparoundCriticalPct     = 75
+)
+
+// healthRuleInputs returns the archive paths read by any health rule
+func healthRuleInputs() map[string]bool {
+	inputs := make(map[string]bool)
+	for _, r := range healthRules {
+		for _, p := range r.Inputs {
+			inputs[p] = true
+		}
+	}
+	return inputs
+}
+
+// evaluateRules runs every health rule and returns findings, most severe first.
+func evaluateRules(a *radarArchive) []Finding {
+	findings := []Finding{}
+	for _, r := range healthRules {
+		for _, f := range r.Check(a) {
+			f.Rule = r.Name
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	return findings
+}
+
+// writeFindingsText writes the human-readable findings summary.
+func writeFindingsText(findings []Finding, w io.Writer) error {
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	if _, err := fmt.Fprintf(w, "%d findings: %d critical, %d warning, %d info\n",
+		len(findings), counts[SeverityCritical], counts[SeverityWarning], counts[SeverityInfo]); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "\n[%s] %s (%s)\n  Evidence:    %s\n  Remediation: %s\n",
+			strings.ToUpper(f.Severity), f.Title, f.Rule, f.Evidence, f.Remediation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFindings evaluates the health rules over the captured entries and
+// writes findings.json and findings.txt into the archive.
+func writeFindings(cfg *Config, zipWriter *zip.Writer) error {
+	findings := evaluateRules(newRadarArchive("", cfg.Captured, time.Now()))
+	report := FindingsReport{Generated: time.Now().UTC(), Findings: findings}
+
+	for _, entry := range []struct {
+		path  string
+		write func(io.Writer) error
+	}{
+		{FindingsJSONPath, func(w io.Writer) error { return writeFindingsJSON(report, w) }},
+		{FindingsTextPath, func(w io.Writer) error { return writeFindingsText(findings, w) }},
+	} {
+		w, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     entry.path,
+			Method:   DefaultCompressionMethod,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := entry.write(w); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Verbose && len(findings) > 0 {
+		infoLog.Printf("Health check: %d findings (see %s)", len(findings), FindingsTextPath)
+	}
+	return nil
+}
+
+// writeFindingsJSON writes findings.json.
+func writeFindingsJSON(report FindingsReport, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// runCheck implements "radar check": run the health rules against an existing archive.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("radar check", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: radar check [options] <archive.zip>\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	archives, err := parseArchiveArgs(fs, args, 1)
+	if err == nil && *format != "text" && *format != "json" {
+		err = fmt.Errorf("invalid --format %q: must be text or json", *format)
+	}
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		errorLog.Println(err)
+		fs.Usage()
+		return ExitUsageError
+	}
+
+	a, err := loadArchive(archives[0])
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+
+	findings := evaluateRules(a)
+	if *format == "json" {
+		err = writeFindingsJSON(FindingsReport{Archive: a.Path, Generated: time.Now().UTC(), Findings: findings}, os.Stdout)
+	} else {
+		err = writeFindingsText(findings, os.Stdout)
+	}
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	return 0
+}
+
+// settingBytes returns a memory setting from configuration.tsv in bytes.
+func settingBytes(a *radarArchive, name string) (float64, bool) {
+	t, ok := a.table("postgresql/configuration.tsv")
+	if !ok {
+		return 0, false
+	}
+	nameCol, settingCol, unitCol := t.columnIndex("name"), t.columnIndex("setting"), t.columnIndex("unit")
+	if nameCol < 0 || settingCol < 0 {
+		return 0, false
+	}
+	for _, row := range t.Rows {
+		if row[nameCol] != name {
+			continue
+		}
+		v, err := strconv.ParseFloat(row[settingCol], 64)
+		if err != nil {
+			return 0, false
+		}
+		unit := ""
+		if unitCol >= 0 && unitCol < len(row) {
+			unit = row[unitCol]
+		}
+		return v * unitBytes(unit), true
+	}
+	return 0, false
+}
+
+// unitBytes converts a pg_settings memory unit such as "8kB" to bytes.
+func unitBytes(unit string) float64 {
+	multipliers := []struct {
+		suffix string
+		factor float64
+	}{{"kB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40}, {"B", 1}}
+	for _, m := range multipliers {
+		if n, ok := strings.CutSuffix(unit, m.suffix); ok {
+			count := 1.0
+			if n != "" {
+				if v, err := strconv.ParseFloat(n, 64); err == nil {
+					count = v
+				}
+			}
+			return count * m.factor
+		}
+	}
+	return 1
+}
+
+// memTotalBytes returns MemTotal from /proc/meminfo.
+func memTotalBytes(a *radarArchive) (float64, bool) {
+	for _, line := range strings.Split(a.text("system/proc/meminfo.out"), "\n") {
+		if value, ok := strings.CutPrefix(line, "MemTotal:"); ok {
+			if fields := strings.Fields(value); len(fields) > 0 {
+				if kb, err := strconv.ParseFloat(fields[0], 64); err == nil {
+					return kb * 1024, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// isTrue reports whether a TSV boolean is true, in either text rendering.
+func isTrue(s string) bool {
+	return s == "t" || s == "true"
+}
+
+// parseLSN converts a pg_lsn such as "16/B374D848" to a byte position.
+func parseLSN(s string) (uint64, bool) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, false
+	}
+	h, err1 := strconv.ParseUint(hi, 16, 32)
+	l, err2 := strconv.ParseUint(lo, 16, 32)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return h<<32 | l, true
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 GiB.
+func formatBytes(b float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for b >= 1024 && i < len(units)-1 {
+		b /= 1024
+		i++
+	}
+	return strconv.FormatFloat(b, 'f', 1, 64) + " " + units[i]
+}
+
+// checkTransparentHugepage flags THP enabled as "always".
+func checkTransparentHugepage(a *radarArchive) []Finding {
+	for _, line := range strings.Split(a.text("system/sys/kernel_mm_transparent_hugepage.out"), "\n") {
+		if strings.Contains(line, "transparent_hugepage/enabled:") && strings.Contains(line, "[always]") {
+			return []Finding{{
+				Severity:    SeverityWarning,
+				Title:       "Transparent huge pages are set to always",
+				Evidence:    strings.TrimSpace(line),
+				Remediation: "Set transparent_hugepage=never (or madvise) on the kernel command line or via /sys/kernel/mm/transparent_hugepage/enabled; THP compaction causes latency spikes for PostgreSQL.",
+			}}
+		}
+	}
+	return nil
+}
+
+// checkSysctlVM flags swappiness and memory overcommit settings.
+func checkSysctlVM(a *radarArchive) []Finding {
+	values := make(map[string]string)
+	for _, line := range strings.Split(a.text("system/sysctl.out"), "\n") {
+		if name, value, ok := strings.Cut(line, "="); ok {
+			values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		} else if name, value, ok := strings.Cut(line, ":"); ok {
+			// macOS sysctl -a prints "name: value"
+			values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	var findings []Finding
+	if v, err := strconv.Atoi(values["vm.swappiness"]); err == nil && v > SwappinessMax {
+		findings = append(findings, Finding{
+			Severity:    SeverityInfo,
+			Title:       "vm.swappiness is high for a database server",
+			Evidence:    fmt.Sprintf("vm.swappiness = %d", v),
+			Remediation: fmt.Sprintf("Set vm.swappiness to %d or lower so the kernel prefers dropping page cache over swapping out PostgreSQL memory.", SwappinessMax),
+		})
+	}
+	if v, ok := values["vm.overcommit_memory"]; ok && v != "2" {
+		findings = append(findings, Finding{
+			Severity:    SeverityInfo,
+			Title:       "Memory overcommit is allowed",
+			Evidence:    "vm.overcommit_memory = " + v,
+			Remediation: "Consider vm.overcommit_memory = 2 with a suitable vm.overcommit_ratio so allocations fail cleanly instead of the OOM killer terminating the postmaster.",
+		})
+	}
+	return findings
+}
+
+// checkSharedBuffers compares shared_buffers with the host's physical memory.
+func checkSharedBuffers(a *radarArchive) []Finding {
+	sharedBuffers, ok1 := settingBytes(a, "shared_buffers")
+	memTotal, ok2 := memTotalBytes(a)
+	if !ok1 || !ok2 || memTotal == 0 {
+		return nil
+	}
+	pct := sharedBuffers / memTotal * 100
+	evidence := fmt.Sprintf("shared_buffers = %s, MemTotal = %s (%.0f%%)", formatBytes(sharedBuffers), formatBytes(memTotal), pct)
+
+	switch {
+	case pct > SharedBuffersMaxPct:
+		return []Finding{{
+			Severity:    SeverityWarning,
+			Title:       "shared_buffers is a large share of physical memory",
+			Evidence:    evidence,
+			Remediation: "Reduce shared_buffers to about 25% of RAM to leave memory for work_mem, maintenance and the OS page cache.",
+		}}
+	case pct < SharedBuffersMinPct && memTotal >= SharedBuffersMinHostRAM:
+		return []Finding{{
+			Severity:    SeverityInfo,
+			Title:       "shared_buffers is small for this host",
+			Evidence:    evidence,
+			Remediation: "Consider raising shared_buffers towards 25% of RAM.",
+		}}
+	}
+	return nil
+}
+
+// checkInactiveSlots flags inactive replication slots that retain WAL.
+func checkInactiveSlots(a *radarArchive) []Finding {
+	slots, ok := a.table("postgresql/replication_slots.tsv")
+	if !ok {
+		return nil
+	}
+	// A standby's slots retain WAL behind the position it received
+	current, _ := a.value("postgresql/wal_position.tsv", "current_wal_lsn")
+	if current == "" {
+		current, _ = a.value("postgresql/wal_position.tsv", "last_wal_receive_lsn")
+	}
+	currentLSN, haveCurrent := parseLSN(current)
+
+	nameCol, activeCol, restartCol := slots.columnIndex("slot_name"), slots.columnIndex("active"), slots.columnIndex("restart_lsn")
+	if nameCol < 0 || activeCol < 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, row := range slots.Rows {
+		if isTrue(row[activeCol]) {
+			continue
+		}
+		f := Finding{
+			Severity:    SeverityWarning,
+			Title:       fmt.Sprintf("Replication slot %q is inactive", row[nameCol]),
+			Evidence:    "active = " + row[activeCol],
+			Remediation: "Reconnect the consumer or drop the slot with pg_drop_replication_slot(); inactive slots keep WAL and block vacuum cleanup.",
+		}
+		if restartCol >= 0 && haveCurrent {
+			if restartLSN, ok := parseLSN(row[restartCol]); ok && currentLSN >= restartLSN {
+				retained := float64(currentLSN - restartLSN)
+				f.Evidence = fmt.Sprintf("active = %s, restart_lsn = %s, retaining %s of WAL", row[activeCol], row[restartCol], formatBytes(retained))
+				if retained >= InactiveSlotWarnBytes {
+					f.Severity = SeverityCritical
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// checkChecksumFailures flags databases that reported data checksum failures.
+func checkChecksumFailures(a *radarArchive) []Finding {
+	t, ok := a.table("postgresql/databases_checksums.tsv")
+	if !ok {
+		return nil
+	}
+	nameCol, failCol, lastCol := t.columnIndex("datname"), t.columnIndex("checksum_failures"), t.columnIndex("checksum_last_failure")
+	if nameCol < 0 || failCol < 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, row := range t.Rows {
+		n, err := strconv.Atoi(row[failCol])
+		if err != nil || n == 0 {
+			continue
+		}
+		evidence := fmt.Sprintf("checksum_failures = %d", n)
+		if lastCol >= 0 && row[lastCol] != "" {
+			evidence += ", last failure " + row[lastCol]
+		}
+		findings = append(findings, Finding{
+			Severity:    SeverityCritical,
+			Title:       fmt.Sprintf("Data checksum failures in database %q", row[nameCol]),
+			Evidence:    evidence,
+			Remediation: "Check the server log for the affected relations, verify storage health, and restore damaged relations from backup or a healthy standby.",
+		})
+	}
+	return findings
+}
+
+// checkNVMeScheduler flags NVMe devices not using the "none" I/O scheduler.
+func checkNVMeScheduler(a *radarArchive) []Finding {
+	var devices []string
+	for _, line := range strings.Split(a.text("system/io_schedulers.out"), "\n") {
+		device, schedulers, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(device, "nvme") {
+			continue
+		}
+		if !strings.Contains(schedulers, "[none]") && strings.Contains(schedulers, "[") {
+			devices = append(devices, strings.TrimSpace(line))
+		}
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	return []Finding{{
+		Severity:    SeverityInfo,
+		Title:       "NVMe devices use an I/O scheduler",
+		Evidence:    strings.Join(devices, "; "),
+		Remediation: "Use the none scheduler for NVMe devices (e.g. a udev rule setting queue/scheduler); NVMe queues do not benefit from request reordering.",
+	}}
+}
+
+// checkCgroupMemoryMax flags a cgroup memory limit that cannot hold shared_buffers.
+func checkCgroupMemoryMax(a *radarArchive) []Finding {
+	limitText := strings.TrimSpace(a.text("system/cgroup/memory_max.out"))
+	limit, err := strconv.ParseFloat(limitText, 64)
+	if err != nil {
+		// Missing or "max" (unlimited)
+		return nil
+	}
+	sharedBuffers, ok := settingBytes(a, "shared_buffers")
+	if !ok {
+		return nil
+	}
+
+	evidence := fmt.Sprintf("memory.max = %s, shared_buffers = %s", formatBytes(limit), formatBytes(sharedBuffers))
+	switch {
+	case limit <= sharedBuffers:
+		return []Finding{{
+			Severity:    SeverityCritical,
+			Title:       "cgroup memory.max is below shared_buffers",
+			Evidence:    evidence,
+			Remediation: "Raise the container or cgroup memory limit well above shared_buffers, or lower shared_buffers; otherwise the OOM killer will terminate backends.",
+		}}
+	case limit < sharedBuffers*CgroupSharedBuffersMargin:
+		return []Finding{{
+			Severity:    SeverityWarning,
+			Title:       "cgroup memory.max leaves little room beyond shared_buffers",
+			Evidence:    evidence,
+			Remediation: "Size the memory limit for shared_buffers plus max_connections × work_mem, maintenance_work_mem and the page cache.",
+		}}
+	}
+	return nil
+}
+
+// checkWraparound flags databases whose oldest unfrozen transaction ID or
+// multixact ID is approaching the 2^31 wraparound limit.
+func checkWraparound(a *radarArchive) []Finding {
+	t, ok := a.table("postgresql/wraparound.tsv")
+	if !ok {
+		return nil
+	}
+	nameCol := t.columnIndex("datname")
+	if nameCol < 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, row := range t.Rows {
+		for _, counter := range []struct{ label, age, pct string }{
+			{"transaction ID", "xid_age", "xid_pct_wraparound"},
+			{"multixact ID", "mxid_age", "mxid_pct_wraparound"},
+		} {
+			ageCol, pctCol := t.columnIndex(counter.age), t.columnIndex(counter.pct)
+			if ageCol < 0 || pctCol < 0 {
+				continue
+			}
+			pct, err := strconv.ParseFloat(row[pctCol], 64)
+			if err != nil || pct < WraparoundWarnPct {
+				continue
+			}
+			severity := SeverityWarning
+			if pct >= WraparoundCriticalPct {
+				severity = SeverityCritical
+			}
+			findings = append(findings, Finding{
+				Severity: severity,
+				Title:    fmt.Sprintf("Database %q is approaching %s wraparound", row[nameCol], counter.label),
+				Evidence: fmt.Sprintf("%s = %s, %.1f%% of the 2^31 limit", counter.age, row[ageCol], pct),
+				Remediation: fmt.Sprintf("Find the oldest tables in databases/%s/freeze_age.tsv and run VACUUM (FREEZE) on them; "+
+					"first end long-running or prepared transactions and drop stale replication slots that hold back the xmin horizon.", row[nameCol]),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,208 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testArchive builds an in-memory archive from string entries
+func testArchive(files map[string]string) *radarArchive {
+	data := make(map[string][]byte, len(files))
+	for name, content := range files {
+		data[name] = []byte(content)
+	}
+	return newRadarArchive("test.zip", data, time.Now())
+}
+
+// findingRules returns the rule name and severity of each finding
+func findingRules(findings []Finding) map[string]string {
+	result := make(map[string]string)
+	for _, f := range findings {
+		result[f.Rule] = f.Severity
+	}
+	return result
+}
+
+// TestHealthRules verifies each rule fires on problem data and stays quiet otherwise
+func TestHealthRules(t *testing.T) {
+	const config = "name\tsetting\tunit\tcategory\tshort_desc\nshared_buffers\t262144\t8kB\tMem\tx\n" // 2 GiB
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "thp always",
+			files:    map[string]string{"system/sys/kernel_mm_transparent_hugepage.out": "/sys/kernel/mm/transparent_hugepage/enabled:[always] madvise never\n"},
+			expected: map[string]string{"transparent_hugepage": SeverityWarning},
+		},
+		{
+			name:     "thp madvise",
+			files:    map[string]string{"system/sys/kernel_mm_transparent_hugepage.out": "/sys/kernel/mm/transparent_hugepage/enabled:always [madvise] never\n"},
+			expected: map[string]string{},
+		},
+		{
+			name:     "sysctl",
+			files:    map[string]string{"system/sysctl.out": "vm.swappiness = 60\nvm.overcommit_memory = 2\n"},
+			expected: map[string]string{"sysctl_vm": SeverityInfo},
+		},
+		{
+			name: "shared_buffers too large",
+			files: map[string]string{
+				"postgresql/configuration.tsv": config,
+				"system/proc/meminfo.out":      "MemTotal:        4194304 kB\n",
+			},
+			expected: map[string]string{"shared_buffers_memory": SeverityWarning},
+		},
+		{
+			name: "shared_buffers fine",
+			files: map[string]string{
+				"postgresql/configuration.tsv": config,
+				"system/proc/meminfo.out":      "MemTotal:        8388608 kB\n",
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "inactive slot retaining WAL",
+			files: map[string]string{
+				"postgresql/replication_slots.tsv": "slot_name\tactive\trestart_lsn\nstandby1\tt\t1/0\nold\tf\t0/0\n",
+				"postgresql/wal_position.tsv":      "current_wal_lsn\n2/0\n",
+			},
+			expected: map[string]string{"inactive_replication_slots": SeverityCritical},
+		},
+		{
+			name: "inactive slot on a standby",
+			files: map[string]string{
+				"postgresql/replication_slots.tsv": "slot_name\tactive\trestart_lsn\nold\tf\t0/0\n",
+				"postgresql/wal_position.tsv":      "current_wal_lsn\tis_in_recovery\tlast_wal_receive_lsn\n\tt\t2/0\n",
+			},
+			expected: map[string]string{"inactive_replication_slots": SeverityCritical},
+		},
+		{
+			name: "xid wraparound",
+			files: map[string]string{"postgresql/wraparound.tsv": "datname\txid_age\txid_pct_wraparound\tmxid_age\tmxid_pct_wraparound\n" +
+				"app\t1700000000\t79.16\t1000\t0.00\npostgres\t200000000\t9.31\t1000\t0.00\n"},
+			expected: map[string]string{"xid_wraparound": SeverityCritical},
+		},
+		{
+			name:     "xid age below threshold",
+			files:    map[string]string{"postgresql/wraparound.tsv": "datname\txid_age\txid_pct_wraparound\tmxid_age\tmxid_pct_wraparound\napp\t200000000\t9.31\t\t\n"},
+			expected: map[string]string{},
+		},
+		{
+			name:     "checksum failures",
+			files:    map[string]string{"postgresql/databases_checksums.tsv": "datname\tchecksum_failures\tchecksum_last_failure\napp\t3\t2026-01-01\npostgres\t0\t\n"},
+			expected: map[string]string{"checksum_failures": SeverityCritical},
+		},
+		{
+			name:     "nvme scheduler",
+			files:    map[string]string{"system/io_schedulers.out": "nvme0n1: [mq-deadline] kyber none\nsda: [mq-deadline] none\n"},
+			expected: map[string]string{"nvme_io_scheduler": SeverityInfo},
+		},
+		{
+			name: "cgroup below shared_buffers",
+			files: map[string]string{
+				"postgresql/configuration.tsv": config,
+				"system/cgroup/memory_max.out": "1073741824\n",
+			},
+			expected: map[string]string{"cgroup_memory_max": SeverityCritical},
+		},
+		{
+			name: "cgroup unlimited",
+			files: map[string]string{
+				"postgresql/configuration.tsv": config,
+				"system/cgroup/memory_max.out": "max\n",
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingRules(evaluateRules(testArchive(tt.files)))
+			if len(got) != len(tt.expected) {
+				t.Errorf("expected findings %v, got %v", tt.expected, got)
+			}
+			for rule, severity := range tt.expected {
+				if got[rule] != severity {
+					t.Errorf("%s: expected %q, got %q", rule, severity, got[rule])
+				}
+			}
+		})
+	}
+}
+
+// TestHealthRuleInputs verifies every rule input is captured during collection
+func TestHealthRuleInputs(t *testing.T) {
+	inputs := healthRuleInputs()
+	for _, r := range healthRules {
+		if len(r.Inputs) == 0 {
+			t.Errorf("rule %q declares no inputs", r.Name)
+		}
+		for _, p := range r.Inputs {
+			if !inputs[p] {
+				t.Errorf("input %q of rule %q missing", p, r.Name)
+			}
+		}
+	}
+}
+
+// TestWriteFindings verifies findings are evaluated from captured entries and written to the archive
+func TestWriteFindings(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	cfg := &Config{Captured: map[string][]byte{
+		"postgresql/databases_checksums.tsv": []byte("datname\tchecksum_failures\napp\t1\n"),
+	}}
+	if err := writeFindings(cfg, zw); err != nil {
+		t.Fatalf("writeFindings failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		closeErrCheck(rc, f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(data)
+	}
+
+	var report FindingsReport
+	if err := json.Unmarshal([]byte(entries[FindingsJSONPath]), &report); err != nil {
+		t.Fatalf("invalid findings.json: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Rule != "checksum_failures" {
+		t.Errorf("unexpected findings %+v", report.Findings)
+	}
+	if !strings.HasPrefix(entries[FindingsTextPath], "1 findings: 1 critical") {
+		t.Errorf("unexpected findings.txt %q", entries[FindingsTextPath])
+	}
+}
@@ -0,0 +1,335 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// Import tables
+const (
+	ImportArchivesTable = "radar_archives" // One row per imported archive, in the public schema
+	ImportRawFilesTable = "raw_files"      // Non-TSV entries of an archive, in its schema
+	ImportMaxParams     = 65535            // Bind parameters per INSERT statement
+	ImportMaxBatchRows  = 1000
+	PGMaxIdentifierLen  = 63
+)
+
+// importPerDatabaseDirs hold one TSV per database; these are merged into a
+// single table with a leading datname column
+var importPerDatabaseDirs = map[string]bool{"databases": true, "pg_statviz": true}
+
+// importArchivesDDL creates the table listing every imported archive
+const importArchivesDDL = `CREATE TABLE IF NOT EXISTS public.` + ImportArchivesTable + ` (
+    schema_name text PRIMARY KEY,
+    archive text NOT NULL,
+    collected_at timestamptz,
+    imported_at timestamptz NOT NULL DEFAULT now(),
+    tables integer NOT NULL,
+    raw_files integer NOT NULL
+)`
+
+// importStats summarizes an import
+type importStats struct {
+	Tables   int
+	Rows     int
+	RawFiles int
+}
+
+// runImport implements the "radar import" subcommand.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("radar import", flag.ContinueOnError)
+	into := fs.String("into", "", "connection string of the analysis database, e.g. postgres://user@host/radar")
+	schema := fs.String("schema", "", "schema to import into (default: derived from the archive name)")
+	replace := fs.Bool("replace", false, "drop and recreate the schema if it already exists")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: radar import [options] <archive.zip> --into <connection string>\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	archives, err := parseArchiveArgs(fs, args, 1)
+	if err == nil && *into == "" {
+		err = fmt.Errorf("--into is required")
+	}
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		errorLog.Println(err)
+		fs.Usage()
+		return ExitUsageError
+	}
+
+	a, err := loadArchive(archives[0])
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	if *schema == "" {
+		*schema = importSchemaName(a.Path)
+	}
+
+	db, err := sql.Open("pgx", *into)
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		errorLog.Printf("Could not connect to the analysis database: %v", err)
+		return ExitCollectError
+	}
+	defer closeErrCheck(db, "database connection")
+
+	stats, err := importArchive(db, a, *schema, *replace)
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	infoLog.Printf("Imported %s into schema %s: %d tables, %d rows, %d raw files",
+		path.Base(a.Path), *schema, stats.Tables, stats.Rows, stats.RawFiles)
+	return 0
+}
+
+// importSchemaName derives a schema name from an archive file name.
+func importSchemaName(archivePath string) string {
+	return sanitizeIdentifier(strings.TrimSuffix(path.Base(archivePath), ".zip"))
+}
+
+// importTableName maps a TSV entry to its table. Entries of per-database
+// directories map to a shared table and also return the database name.
+func importTableName(entry string) (table, datname string) {
+	parts := strings.Split(strings.TrimSuffix(entry, ".tsv"), "/")
+	if len(parts) == 3 && importPerDatabaseDirs[parts[0]] {
+		return sanitizeIdentifier(parts[0] + "_" + parts[2]), parts[1]
+	}
+	return sanitizeIdentifier(strings.Join(parts, "_")), ""
+}
+
+// sanitizeIdentifier lowercases a name and replaces characters that would
+// need quoting, so that imported tables are easy to query.
+func sanitizeIdentifier(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return '_'
+	}, s)
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	if len(s) > PGMaxIdentifierLen {
+		s = s[:PGMaxIdentifierLen]
+	}
+	return s
+}
+
+// quoteIdent quotes a PostgreSQL identifier.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// importColumns makes TSV header names usable as column names: empty names
+// are numbered, long names truncated and duplicates suffixed.
+func importColumns(header []string) []string {
+	seen := make(map[string]bool)
+	columns := make([]string, len(header))
+	for i, name := range header {
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		if len(name) > PGMaxIdentifierLen {
+			name = name[:PGMaxIdentifierLen]
+		}
+		base := name
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	return columns
+}
+
+// importArchive loads an archive into its own schema in a single transaction.
+// Every TSV entry becomes a table of text columns, with empty fields loaded as
+// NULL; all other entries are stored in raw_files.
+func importArchive(db *sql.DB, a *radarArchive, schema string, replace bool) (importStats, error) {
+	var stats importStats
+
+	tx, err := db.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer func() {
+		// No-op after a successful commit
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(importArchivesDDL); err != nil {
+		return stats, fmt.Errorf("creating %s: %w", ImportArchivesTable, err)
+	}
+	if replace {
+		if _, err := tx.Exec("DROP SCHEMA IF EXISTS " + quoteIdent(schema) + " CASCADE"); err != nil {
+			return stats, fmt.Errorf("dropping schema %s: %w", schema, err)
+		}
+		if _, err := tx.Exec("DELETE FROM public."+ImportArchivesTable+" WHERE schema_name = $1", schema); err != nil {
+			return stats, err
+		}
+	}
+	if _, err := tx.Exec("CREATE SCHEMA " + quoteIdent(schema)); err != nil {
+		return stats, fmt.Errorf("creating schema %s (use --replace to overwrite): %w", schema, err)
+	}
+
+	// Columns of the tables created so far, to extend them when entries
+	// sharing a table have different columns
+	tables := make(map[string][]string)
+	var rawFiles [][]string
+
+	for _, name := range a.Names {
+		if !strings.HasSuffix(name, ".tsv") {
+			rawFiles = append(rawFiles, []string{name, importText(a.Files[name])})
+			continue
+		}
+		t, err := parseTSV(a.Files[name])
+		if err != nil || len(t.Columns) == 0 {
+			// Not a TSV written by radar; keep its content
+			rawFiles = append(rawFiles, []string{name, importText(a.Files[name])})
+			continue
+		}
+
+		table, datname := importTableName(name)
+		header, rows := t.Columns, t.Rows
+		if datname != "" {
+			header = append([]string{"datname"}, header...)
+			rows = make([][]string, len(t.Rows))
+			for i, row := range t.Rows {
+				rows[i] = append([]string{datname}, row...)
+			}
+		}
+		columns := importColumns(header)
+
+		if err := importEnsureTable(tx, schema, table, columns, tables); err != nil {
+			return stats, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := importRows(tx, schema, table, columns, rows, true); err != nil {
+			return stats, fmt.Errorf("%s: %w", name, err)
+		}
+		stats.Rows += len(rows)
+	}
+	stats.Tables = len(tables)
+
+	if _, err := tx.Exec("CREATE TABLE " + quoteIdent(schema) + "." + quoteIdent(ImportRawFilesTable) + " (path text PRIMARY KEY, content text)"); err != nil {
+		return stats, fmt.Errorf("creating %s: %w", ImportRawFilesTable, err)
+	}
+	if err := importRows(tx, schema, ImportRawFilesTable, []string{"path", "content"}, rawFiles, false); err != nil {
+		return stats, fmt.Errorf("%s: %w", ImportRawFilesTable, err)
+	}
+	stats.RawFiles = len(rawFiles)
+
+	var collected interface{}
+	if !a.Collected.IsZero() {
+		collected = a.Collected
+	}
+	if _, err := tx.Exec("INSERT INTO public."+ImportArchivesTable+
+		" (schema_name, archive, collected_at, tables, raw_files) VALUES ($1, $2, $3, $4, $5)",
+		schema, path.Base(a.Path), collected, stats.Tables, stats.RawFiles); err != nil {
+		return stats, fmt.Errorf("recording archive: %w", err)
+	}
+
+	return stats, tx.Commit()
+}
+
+// importEnsureTable creates a table of text columns, or adds the columns
+// missing from a table created earlier in the import.
+func importEnsureTable(tx *sql.Tx, schema, table string, columns []string, tables map[string][]string) error {
+	qualified := quoteIdent(schema) + "." + quoteIdent(table)
+	existing, ok := tables[table]
+	if !ok {
+		defs := make([]string, len(columns))
+		for i, c := range columns {
+			defs[i] = quoteIdent(c) + " text"
+		}
+		if _, err := tx.Exec("CREATE TABLE " + qualified + " (" + strings.Join(defs, ", ") + ")"); err != nil {
+			return fmt.Errorf("creating table %s: %w", table, err)
+		}
+		tables[table] = columns
+		return nil
+	}
+	for _, c := range columns {
+		if containsString(existing, c) {
+			continue
+		}
+		if _, err := tx.Exec("ALTER TABLE " + qualified + " ADD COLUMN " + quoteIdent(c) + " text"); err != nil {
+			return fmt.Errorf("extending table %s: %w", table, err)
+		}
+		existing = append(existing, c)
+	}
+	tables[table] = existing
+	return nil
+}
+
+// importRows inserts rows in multi-row INSERT statements.
+func importRows(tx *sql.Tx, schema, table string, columns []string, rows [][]string, emptyAsNull bool) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	prefix := "INSERT INTO " + quoteIdent(schema) + "." + quoteIdent(table) + " (" + strings.Join(quoted, ", ") + ") VALUES "
+	batch := min(ImportMaxBatchRows, ImportMaxParams/len(columns))
+
+	for start := 0; start < len(rows); start += batch {
+		end := min(start+batch, len(rows))
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('(')
+			for j := range columns {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				var v interface{}
+				if j < len(row) && (row[j] != "" || !emptyAsNull) {
+					v = row[j]
+				}
+				args = append(args, v)
+				fmt.Fprintf(&sb, "$%d", len(args))
+			}
+			sb.WriteByte(')')
+		}
+		if _, err := tx.Exec(sb.String(), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importText makes entry content storable as text: invalid UTF-8 is replaced
+// and NUL bytes, which text values cannot hold, are dropped.
+func importText(data []byte) string {
+	s := string(data)
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "�")
+	}
+	return strings.ReplaceAll(s, "\x00", "")
+}
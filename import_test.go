@@ -0,0 +1,85 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestImportArchive verifies schema, table and raw_files statements of an import
+func TestImportArchive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	a := testArchive(map[string]string{
+		"databases/app/tables.tsv":      "schemaname\ttablename\npublic\torders\n",
+		"databases/postgres/tables.tsv": "schemaname\ttablename\thasindexes\npublic\tt\tf\n",
+		"postgresql/configuration.tsv":  "name\tsetting\tunit\nwork_mem\t4096\t\n",
+		"system/uname.out":              "Linux db1\n",
+	})
+	exec := func(sql string) *sqlmock.ExpectedExec {
+		return mock.ExpectExec(regexp.QuoteMeta(sql))
+	}
+
+	mock.ExpectBegin()
+	exec("CREATE TABLE IF NOT EXISTS public.radar_archives").WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`DROP SCHEMA IF EXISTS "radar_db1" CASCADE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec("DELETE FROM public.radar_archives WHERE schema_name = $1").WithArgs("radar_db1").WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`CREATE SCHEMA "radar_db1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`CREATE TABLE "radar_db1"."databases_tables" ("datname" text, "schemaname" text, "tablename" text)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`INSERT INTO "radar_db1"."databases_tables" ("datname", "schemaname", "tablename") VALUES ($1, $2, $3)`).
+		WithArgs("app", "public", "orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	exec(`ALTER TABLE "radar_db1"."databases_tables" ADD COLUMN "hasindexes" text`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`INSERT INTO "radar_db1"."databases_tables" ("datname", "schemaname", "tablename", "hasindexes") VALUES ($1, $2, $3, $4)`).
+		WithArgs("postgres", "public", "t", "f").WillReturnResult(sqlmock.NewResult(0, 1))
+	exec(`CREATE TABLE "radar_db1"."postgresql_configuration" ("name" text, "setting" text, "unit" text)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`INSERT INTO "radar_db1"."postgresql_configuration" ("name", "setting", "unit") VALUES ($1, $2, $3)`).
+		WithArgs("work_mem", "4096", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	exec(`CREATE TABLE "radar_db1"."raw_files" (path text PRIMARY KEY, content text)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	exec(`INSERT INTO "radar_db1"."raw_files" ("path", "content") VALUES ($1, $2)`).
+		WithArgs("system/uname.out", "Linux db1\n").WillReturnResult(sqlmock.NewResult(0, 1))
+	exec("INSERT INTO public.radar_archives").WithArgs("radar_db1", "test.zip", sqlmock.AnyArg(), 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	stats, err := importArchive(db, a, "radar_db1", true)
+	if err != nil {
+		t.Fatalf("importArchive failed: %v", err)
+	}
+	if stats != (importStats{Tables: 2, Rows: 3, RawFiles: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestImportNames verifies schema, table and column naming
+func TestImportNames(t *testing.T) {
+	if got := importSchemaName("/tmp/radar-db1.example-20260115-133700.zip"); got != "radar_db1_example_20260115_133700" {
+		t.Errorf("unexpected schema name %q", got)
+	}
+	if table, datname := importTableName("pg_statviz/app/conn.tsv"); table != "pg_statviz_conn" || datname != "app" {
+		t.Errorf("unexpected per-database table %q, %q", table, datname)
+	}
+	if table, datname := importTableName("system/proc/diskstats_rate.tsv"); table != "system_proc_diskstats_rate" || datname != "" {
+		t.Errorf("unexpected table %q, %q", table, datname)
+	}
+	got := importColumns([]string{"x", "", "x"})
+	if got[0] != "x" || got[1] != "column_2" || got[2] != "x_2" {
+		t.Errorf("unexpected columns %q", got)
+	}
+}
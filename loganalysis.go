@@ -0,0 +1,727 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogAnalysisDir holds the summaries of the collected server logs
+const LogAnalysisDir = "postgresql/log_analysis"
+
+// logAnalysisFiles are the summaries written to LogAnalysisDir, as name.tsv
+var logAnalysisFiles = []string{
+	"summary", "errors", "connection_failures", "checkpoints", "autovacuum",
+	"temp_files", "lock_waits", "slow_statements",
+}
+
+// logEntry is one server log message with its DETAIL and STATEMENT lines
+type logEntry struct {
+	Time      string
+	User      string
+	Database  string
+	Host      string
+	PID       string
+	SQLState  string
+	Severity  string
+	Message   string
+	Detail    string
+	Statement string
+}
+
+// Messages recognized by the analyzer. They are matched in English, so the
+// server must log with lc_messages set to C or an English locale.
+var (
+	logCheckpointStartRe    = regexp.MustCompile(`^(checkpoint|restartpoint) starting: (.*)$`)
+	logCheckpointCompleteRe = regexp.MustCompile(`^(checkpoint|restartpoint) complete: wrote (\d+) buffers.*?write=([\d.]+) s, sync=([\d.]+) s, total=([\d.]+) s`)
+	logCheckpointDistRe     = regexp.MustCompile(`distance=(\d+) kB`)
+	logCheckpointFreqRe     = regexp.MustCompile(`^checkpoints are occurring too frequently \((\d+) seconds? apart\)`)
+	logAutovacuumRe         = regexp.MustCompile(`^automatic (?:aggressive )?(vacuum|analyze)(?: to prevent wraparound)? of table "([^"]+)"`)
+	logElapsedRe            = regexp.MustCompile(`elapsed: ([\d.]+) s`)
+	logTempFileRe           = regexp.MustCompile(`^temporary file: path "[^"]*", size (\d+)`)
+	logLockWaitRe           = regexp.MustCompile(`^process (\d+) (still waiting for|acquired) (\S+) on (.+?) after ([\d.]+) ms`)
+	logDurationRe           = regexp.MustCompile(`(?s)^duration: ([\d.]+) ms  (?:statement|execute [^:]*|parse [^:]*|bind [^:]*): (.*)$`)
+	logUserRe               = regexp.MustCompile(`user "([^"]*)"`)
+	logHostRe               = regexp.MustCompile(`host "([^"]*)"`)
+	logDatabaseRe           = regexp.MustCompile(`database "([^"]*)"`)
+	logQuotedRe             = regexp.MustCompile(`"[^"]*"`)
+	logNumberRe             = regexp.MustCompile(`\b\d+\b`)
+)
+
+// logConnectionFailureStates are the SQLSTATEs of rejected connections:
+// authentication, unknown database, too many connections, startup/shutdown
+var logConnectionFailureStates = map[string]bool{
+	"28000": true, "28P01": true, "3D000": true, "53300": true, "57P03": true,
+}
+
+// logConnectionFailureMessages identify rejected connections in logs
+// without SQLSTATEs
+var logConnectionFailureMessages = []string{
+	"password authentication failed", "authentication failed for user",
+	"no pg_hba.conf entry", "pg_hba.conf rejects connection",
+	"role \"", "database \"", "too many clients", "remaining connection slots",
+	"the database system is starting up", "the database system is shutting down",
+}
+
+// logCount counts occurrences of one key
+type logCount struct {
+	example     string
+	count       int
+	first, last string
+}
+
+// add counts an entry logged at t.
+func (c *logCount) add(t string) {
+	c.count++
+	if c.first == "" || (t != "" && t < c.first) {
+		c.first = t
+	}
+	if t > c.last {
+		c.last = t
+	}
+}
+
+// logAnalyzer summarizes the server log files as their collectors write them
+type logAnalyzer struct {
+	format string // Log format analyzed: stderr, csvlog or jsonlog
+	prefix *logPrefix
+	topN   int
+
+	files    int
+	entries  int
+	first    string
+	last     string
+	severity map[string]int
+
+	errors           map[[3]string]*logCount
+	connFailures     map[[5]string]*logCount
+	checkpoints      [][]string
+	checkpointReason map[string]string // Trigger of the checkpoint started, by kind
+	autovacuum       map[[2]string]*autovacuumStats
+	tempFiles        map[string]*tempFileStats
+	lockWaits        [][]string
+	slow             []slowStatement
+}
+
+type autovacuumStats struct {
+	runs         int
+	totalElapsed float64
+	maxElapsed   float64
+	last         string
+}
+
+type tempFileStats struct {
+	count      int
+	totalBytes int64
+	maxBytes   int64
+}
+
+type slowStatement struct {
+	time, user, database string
+	duration             float64
+	statement            string
+}
+
+// newLogAnalyzer creates an analyzer of the log files in format, reading
+// stderr lines according to logLinePrefix.
+func newLogAnalyzer(format, logLinePrefix string, topN int) *logAnalyzer {
+	if topN < 1 {
+		topN = DefaultTopN
+	}
+	return &logAnalyzer{
+		format:           format,
+		prefix:           compileLogPrefix(logLinePrefix),
+		topN:             topN,
+		severity:         make(map[string]int),
+		errors:           make(map[[3]string]*logCount),
+		connFailures:     make(map[[5]string]*logCount),
+		checkpointReason: make(map[string]string),
+		autovacuum:       make(map[[2]string]*autovacuumStats),
+		tempFiles:        make(map[string]*tempFileStats),
+	}
+}
+
+// add parses the content of a log file written in format; files of other
+// formats are ignored, as they repeat the same messages. journal marks
+// journalctl output, whose lines start with the journal's own prefix.
+func (a *logAnalyzer) add(format string, data []byte, journal bool) {
+	if format != a.format {
+		return
+	}
+	a.files++
+	switch format {
+	case "csvlog":
+		parseCSVLog(data, a.analyze)
+	case "jsonlog":
+		parseJSONLog(data, a.analyze)
+	default:
+		a.prefix.parse(data, journal, a.analyze)
+	}
+}
+
+// logFormatOf returns the log format of a log file from its extension.
+func logFormatOf(path string) string {
+	for _, f := range logFormats {
+		if f.ext != "" && strings.HasSuffix(path, f.ext) {
+			return f.name
+		}
+	}
+	return "stderr"
+}
+
+// analyze adds one log entry to the summaries.
+func (a *logAnalyzer) analyze(e *logEntry) {
+	a.entries++
+	a.severity[e.Severity]++
+	if e.Time != "" {
+		if a.first == "" || e.Time < a.first {
+			a.first = e.Time
+		}
+		if e.Time > a.last {
+			a.last = e.Time
+		}
+	}
+
+	switch e.Severity {
+	case "ERROR", "FATAL", "PANIC":
+		key := [3]string{e.Severity, e.SQLState, ""}
+		if e.SQLState == "" {
+			key[2] = normalizeLogMessage(e.Message)
+		}
+		countLog(a.errors, key, e)
+		if e.Severity == "FATAL" && isConnectionFailure(e) {
+			a.addConnectionFailure(e)
+		}
+		if e.SQLState == "40P01" || strings.HasPrefix(e.Message, "deadlock detected") {
+			a.lockWaits = append(a.lockWaits, []string{e.Time, "deadlock", e.PID, "", "", "", e.Detail, oneLine(e.Statement)})
+		}
+		return
+	}
+
+	msg := e.Message
+	if m := logCheckpointStartRe.FindStringSubmatch(msg); m != nil {
+		a.checkpointReason[m[1]] = m[2]
+	} else if m := logCheckpointCompleteRe.FindStringSubmatch(msg); m != nil {
+		distance := ""
+		if d := logCheckpointDistRe.FindStringSubmatch(msg); d != nil {
+			distance = d[1]
+		}
+		a.checkpoints = append(a.checkpoints, []string{e.Time, m[1], a.checkpointReason[m[1]], m[2], m[3], m[4], m[5], distance, ""})
+		delete(a.checkpointReason, m[1])
+	} else if m := logCheckpointFreqRe.FindStringSubmatch(msg); m != nil {
+		a.checkpoints = append(a.checkpoints, []string{e.Time, "too_frequent", "", "", "", "", "", "", m[1]})
+	} else if m := logAutovacuumRe.FindStringSubmatch(msg); m != nil {
+		key := [2]string{m[2], m[1]}
+		s := a.autovacuum[key]
+		if s == nil {
+			s = &autovacuumStats{}
+			a.autovacuum[key] = s
+		}
+		s.runs++
+		if el := logElapsedRe.FindStringSubmatch(msg); el != nil {
+			elapsed, _ := strconv.ParseFloat(el[1], 64)
+			s.totalElapsed += elapsed
+			s.maxElapsed = max(s.maxElapsed, elapsed)
+		}
+		if e.Time > s.last {
+			s.last = e.Time
+		}
+	} else if m := logTempFileRe.FindStringSubmatch(msg); m != nil {
+		size, _ := strconv.ParseInt(m[1], 10, 64)
+		statement := oneLine(e.Statement)
+		s := a.tempFiles[statement]
+		if s == nil {
+			s = &tempFileStats{}
+			a.tempFiles[statement] = s
+		}
+		s.count++
+		s.totalBytes += size
+		s.maxBytes = max(s.maxBytes, size)
+	} else if m := logLockWaitRe.FindStringSubmatch(msg); m != nil {
+		kind := "waiting"
+		if m[2] == "acquired" {
+			kind = "acquired"
+		}
+		a.lockWaits = append(a.lockWaits, []string{e.Time, kind, m[1], m[3], m[4], m[5], e.Detail, oneLine(e.Statement)})
+	} else if m := logDurationRe.FindStringSubmatch(msg); m != nil {
+		duration, _ := strconv.ParseFloat(m[1], 64)
+		a.slow = append(a.slow, slowStatement{e.Time, e.User, e.Database, duration, oneLine(m[2])})
+	}
+}
+
+// addConnectionFailure counts a rejected connection by user, host and
+// database, taken from the message when log_line_prefix lacks them.
+func (a *logAnalyzer) addConnectionFailure(e *logEntry) {
+	user, host, database := e.User, e.Host, e.Database
+	if m := logUserRe.FindStringSubmatch(e.Message); m != nil && user == "" {
+		user = m[1]
+	}
+	if m := logHostRe.FindStringSubmatch(e.Message); m != nil && host == "" {
+		host = m[1]
+	}
+	if m := logDatabaseRe.FindStringSubmatch(e.Message); m != nil && database == "" {
+		database = m[1]
+	}
+	key := [5]string{user, host, database, e.SQLState, normalizeLogMessage(e.Message)}
+	countLog(a.connFailures, key, e)
+}
+
+// countLog counts e under key, keeping its message as the example.
+func countLog[K comparable](counts map[K]*logCount, key K, e *logEntry) {
+	c := counts[key]
+	if c == nil {
+		c = &logCount{example: e.Message}
+		counts[key] = c
+	}
+	c.add(e.Time)
+}
+
+// isConnectionFailure reports whether a FATAL entry rejected a connection.
+func isConnectionFailure(e *logEntry) bool {
+	if e.SQLState != "" {
+		return logConnectionFailureStates[e.SQLState]
+	}
+	for _, pattern := range logConnectionFailureMessages {
+		if strings.Contains(e.Message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLogMessage replaces quoted names and numbers, so that messages
+// differing only in them are counted together.
+func normalizeLogMessage(msg string) string {
+	msg, _, _ = strings.Cut(msg, "\n")
+	msg = logQuotedRe.ReplaceAllString(msg, `"?"`)
+	return logNumberRe.ReplaceAllString(msg, "N")
+}
+
+// oneLine collapses the whitespace of a statement.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tables returns the summaries by file name.
+func (a *logAnalyzer) tables() map[string]*tsvTable {
+	tables := make(map[string]*tsvTable)
+
+	summary := &tsvTable{Columns: []string{"metric", "value"}}
+	addMetric := func(name string, value interface{}) {
+		summary.Rows = append(summary.Rows, []string{name, fmt.Sprint(value)})
+	}
+	addMetric("log_format", a.format)
+	addMetric("log_files", a.files)
+	addMetric("entries", a.entries)
+	addMetric("first_entry", a.first)
+	addMetric("last_entry", a.last)
+	for _, severity := range []string{"PANIC", "FATAL", "ERROR", "WARNING", "LOG"} {
+		addMetric(strings.ToLower(severity), a.severity[severity])
+	}
+	connFailures := 0
+	for _, c := range a.connFailures {
+		connFailures += c.count
+	}
+	addMetric("connection_failures", connFailures)
+	checkpoints, tooFrequent := 0, 0
+	for _, row := range a.checkpoints {
+		if row[1] == "too_frequent" {
+			tooFrequent++
+		} else {
+			checkpoints++
+		}
+	}
+	addMetric("checkpoints", checkpoints)
+	addMetric("checkpoints_too_frequent", tooFrequent)
+	autovacuumRuns := 0
+	for _, s := range a.autovacuum {
+		autovacuumRuns += s.runs
+	}
+	addMetric("autovacuum_runs", autovacuumRuns)
+	var tempFiles int
+	var tempBytes int64
+	for _, s := range a.tempFiles {
+		tempFiles += s.count
+		tempBytes += s.totalBytes
+	}
+	addMetric("temp_files", tempFiles)
+	addMetric("temp_bytes", tempBytes)
+	lockWaits, deadlocks := 0, 0
+	for _, row := range a.lockWaits {
+		if row[1] == "deadlock" {
+			deadlocks++
+		} else if row[1] == "waiting" {
+			lockWaits++
+		}
+	}
+	addMetric("lock_waits", lockWaits)
+	addMetric("deadlocks", deadlocks)
+	addMetric("slow_statements", len(a.slow))
+	tables["summary"] = summary
+
+	errorsTable := &tsvTable{Columns: []string{"severity", "sqlstate", "count", "first_seen", "last_seen", "message"}}
+	for key, c := range a.errors {
+		errorsTable.Rows = append(errorsTable.Rows, []string{key[0], key[1], strconv.Itoa(c.count), c.first, c.last, oneLine(c.example)})
+	}
+	tables["errors"] = a.topByNumber(errorsTable, 2)
+
+	conn := &tsvTable{Columns: []string{"user", "host", "database", "sqlstate", "count", "first_seen", "last_seen", "message"}}
+	for key, c := range a.connFailures {
+		conn.Rows = append(conn.Rows, []string{key[0], key[1], key[2], key[3], strconv.Itoa(c.count), c.first, c.last, oneLine(c.example)})
+	}
+	tables["connection_failures"] = a.topByNumber(conn, 4)
+
+	tables["checkpoints"] = &tsvTable{
+		Columns: []string{"log_time", "kind", "trigger", "buffers", "write_s", "sync_s", "total_s", "distance_kb", "seconds_apart"},
+		Rows:    a.checkpoints,
+	}
+
+	av := &tsvTable{Columns: []string{"table", "kind", "runs", "total_elapsed_s", "max_elapsed_s", "last_run"}}
+	for key, s := range a.autovacuum {
+		av.Rows = append(av.Rows, []string{key[0], key[1], strconv.Itoa(s.runs),
+			strconv.FormatFloat(s.totalElapsed, 'f', 2, 64), strconv.FormatFloat(s.maxElapsed, 'f', 2, 64), s.last})
+	}
+	tables["autovacuum"] = a.topByNumber(av, 3)
+
+	temp := &tsvTable{Columns: []string{"statement", "count", "total_bytes", "max_bytes"}}
+	for statement, s := range a.tempFiles {
+		temp.Rows = append(temp.Rows, []string{statement, strconv.Itoa(s.count),
+			strconv.FormatInt(s.totalBytes, 10), strconv.FormatInt(s.maxBytes, 10)})
+	}
+	tables["temp_files"] = a.topByNumber(temp, 2)
+
+	tables["lock_waits"] = &tsvTable{
+		Columns: []string{"log_time", "kind", "pid", "lock_mode", "lock_object", "wait_ms", "detail", "statement"},
+		Rows:    a.lockWaits,
+	}
+
+	sort.SliceStable(a.slow, func(i, j int) bool { return a.slow[i].duration > a.slow[j].duration })
+	slow := &tsvTable{Columns: []string{"log_time", "user", "database", "duration_ms", "statement"}}
+	for i, s := range a.slow {
+		if i == a.topN {
+			break
+		}
+		slow.Rows = append(slow.Rows, []string{s.time, s.user, s.database, strconv.FormatFloat(s.duration, 'f', 3, 64), s.statement})
+	}
+	tables["slow_statements"] = slow
+	return tables
+}
+
+// topByNumber sorts rows by their numeric column col, descending (ties by
+// the other columns), and keeps the top N.
+func (a *logAnalyzer) topByNumber(t *tsvTable, col int) *tsvTable {
+	sort.Slice(t.Rows, func(i, j int) bool {
+		vi, _ := strconv.ParseFloat(t.Rows[i][col], 64)
+		vj, _ := strconv.ParseFloat(t.Rows[j][col], 64)
+		if vi != vj {
+			return vi > vj
+		}
+		return strings.Join(t.Rows[i], "\t") < strings.Join(t.Rows[j], "\t")
+	})
+	if len(t.Rows) > a.topN {
+		t.Rows = t.Rows[:a.topN]
+	}
+	return t
+}
+
+// logAnalysisCollector creates a collector that writes one summary of the
+// log files analyzed so far. It runs after the log file collectors.
+func logAnalysisCollector(a *logAnalyzer, name string) func(*Config, io.Writer) error {
+	return func(cfg *Config, w io.Writer) error {
+		if a.files == 0 {
+			return NewSkipError("no server log collected")
+		}
+		return a.tables()[name].writeTSV(w)
+	}
+}
+
+// logAnalysisTasks returns the tasks writing the summaries of a.
+func logAnalysisTasks(a *logAnalyzer) []CollectionTask {
+	tasks := make([]CollectionTask, 0, len(logAnalysisFiles))
+	for _, name := range logAnalysisFiles {
+		tasks = append(tasks, CollectionTask{
+			Category:    "postgresql",
+			Name:        "log_analysis/" + name,
+			ArchivePath: LogAnalysisDir + "/" + name + ".tsv",
+			Collector:   logAnalysisCollector(a, name),
+		})
+	}
+	return tasks
+}
+
+// CSV log columns (log_time, user_name, database_name, process_id,
+// connection_from, ..., error_severity, sql_state_code, message, detail,
+// ..., query)
+const (
+	csvLogTime      = 0
+	csvLogUser      = 1
+	csvLogDatabase  = 2
+	csvLogPID       = 3
+	csvLogHost      = 4
+	csvLogSeverity  = 11
+	csvLogSQLState  = 12
+	csvLogMessage   = 13
+	csvLogDetail    = 14
+	csvLogQuery     = 19
+	csvLogMinFields = 20
+)
+
+// parseCSVLog parses a csvlog file. Records cut by the size cap or
+// otherwise malformed are skipped.
+func parseCSVLog(data []byte, fn func(*logEntry)) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return
+		}
+		if err != nil || len(record) < csvLogMinFields {
+			continue
+		}
+		host := record[csvLogHost] // host:port, or [local]
+		if i := strings.LastIndexByte(host, ':'); i >= 0 {
+			host = host[:i]
+		}
+		fn(&logEntry{
+			Time:      record[csvLogTime],
+			User:      record[csvLogUser],
+			Database:  record[csvLogDatabase],
+			Host:      host,
+			PID:       record[csvLogPID],
+			SQLState:  record[csvLogSQLState],
+			Severity:  record[csvLogSeverity],
+			Message:   record[csvLogMessage],
+			Detail:    record[csvLogDetail],
+			Statement: record[csvLogQuery],
+		})
+	}
+}
+
+// jsonLogRecord holds the jsonlog keys the analyzer reads
+type jsonLogRecord struct {
+	Timestamp  string      `json:"timestamp"`
+	User       string      `json:"user"`
+	Database   string      `json:"dbname"`
+	RemoteHost string      `json:"remote_host"`
+	PID        json.Number `json:"pid"`
+	Severity   string      `json:"error_severity"`
+	SQLState   string      `json:"state_code"`
+	Message    string      `json:"message"`
+	Detail     string      `json:"detail"`
+	Statement  string      `json:"statement"`
+}
+
+// parseJSONLog parses a jsonlog file, one object per line.
+func parseJSONLog(data []byte, fn func(*logEntry)) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var rec jsonLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		fn(&logEntry{
+			Time:      rec.Timestamp,
+			User:      rec.User,
+			Database:  rec.Database,
+			Host:      rec.RemoteHost,
+			PID:       rec.PID.String(),
+			SQLState:  rec.SQLState,
+			Severity:  rec.Severity,
+			Message:   rec.Message,
+			Detail:    rec.Detail,
+			Statement: rec.Statement,
+		})
+	}
+}
+
+// logPrefixPatterns are the regular expressions of the log_line_prefix
+// escapes. Text escapes are non-greedy, as they may contain spaces.
+var logPrefixPatterns = map[byte]string{
+	'a': `.*?`, 'u': `.*?`, 'd': `.*?`, 'r': `.*?`, 'h': `.*?`, 'b': `.*?`, 'i': `.*?`,
+	'p': `\d+`, 'P': `\d*`, 'l': `\d+`, 'x': `\d*`, 'v': `\S*`, 'Q': `-?\d*`,
+	'c': `[0-9a-f]*\.[0-9a-f]*`,
+	'e': `[0-9A-Z]{5}`,
+	'm': `\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d+(?: \S+)?`,
+	't': `\d{4}-\d\d-\d\d \d\d:\d\d:\d\d(?: \S+)?`,
+	's': `\d{4}-\d\d-\d\d \d\d:\d\d:\d\d(?: \S+)?`,
+	'n': `\d+\.\d+`,
+}
+
+// logPrefixFields are the escapes read into logEntry fields
+const logPrefixFields = "mtnudhrpe"
+
+// logSeverityPattern matches the severity and message after the prefix
+const logSeverityPattern = `(LOG|ERROR|FATAL|PANIC|WARNING|NOTICE|INFO|DEBUG[1-5]?|DETAIL|HINT|CONTEXT|STATEMENT|QUERY|LOCATION):  (.*)$`
+
+// journalLinePrefix matches the prefix of journalctl's short-iso output
+var journalLinePrefix = regexp.MustCompile(`^\S+ \S+ [^\s\[]+\[\d+\]: `)
+
+// logPrefix parses stderr log lines written with a log_line_prefix
+type logPrefix struct {
+	re     *regexp.Regexp
+	fields map[byte]int // Submatch index of each escape read
+}
+
+// compileLogPrefix builds the line pattern of a log_line_prefix. Padded
+// escapes (%-10u) may be surrounded by spaces, and the part after %q is
+// optional, as it is omitted for processes without a session.
+func compileLogPrefix(prefix string) *logPrefix {
+	var b strings.Builder
+	b.WriteString("^")
+	p := &logPrefix{fields: make(map[byte]int)}
+	group, optional := 0, false
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if c != '%' || i+1 == len(prefix) {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+		i++
+		padded := false
+		for i < len(prefix) && (prefix[i] == '-' || (prefix[i] >= '0' && prefix[i] <= '9')) {
+			padded = true
+			i++
+		}
+		if i == len(prefix) {
+			break
+		}
+		escape := prefix[i]
+		switch escape {
+		case '%':
+			b.WriteString("%")
+			continue
+		case 'q':
+			if !optional {
+				b.WriteString("(?:")
+				optional = true
+			}
+			continue
+		}
+		pattern, ok := logPrefixPatterns[escape]
+		if !ok {
+			pattern = `.*?`
+		}
+		if padded {
+			b.WriteString(` *`)
+		}
+		group++
+		b.WriteString("(" + pattern + ")")
+		if padded {
+			b.WriteString(` *`)
+		}
+		if _, seen := p.fields[escape]; !seen && strings.IndexByte(logPrefixFields, escape) >= 0 {
+			p.fields[escape] = group
+		}
+	}
+	if optional {
+		b.WriteString(")?")
+	}
+	b.WriteString(logSeverityPattern)
+	p.re = regexp.MustCompile(b.String())
+	return p
+}
+
+// field returns the value of the first escape of escapes in the prefix.
+func (p *logPrefix) field(m []string, escapes string) string {
+	for i := 0; i < len(escapes); i++ {
+		if g, ok := p.fields[escapes[i]]; ok && g < len(m) {
+			return m[g]
+		}
+	}
+	return ""
+}
+
+// parse splits stderr log output into entries. DETAIL and STATEMENT lines
+// belong to the entry before them, and tab-indented lines continue the
+// line before them.
+func (p *logPrefix) parse(data []byte, journal bool, fn func(*logEntry)) {
+	var current *logEntry
+	var last *string
+	var ignored string
+	flush := func() {
+		if current != nil {
+			fn(current)
+			current = nil
+		}
+	}
+
+	severityGroup := p.re.NumSubexp() - 1
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if journal {
+			line = journalLinePrefix.ReplaceAllString(line, "")
+		}
+		if strings.HasPrefix(line, "\t") {
+			if last != nil {
+				*last += "\n" + line[1:]
+			}
+			continue
+		}
+		m := p.re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		severity, text := m[severityGroup], m[severityGroup+1]
+		switch severity {
+		case "DETAIL", "STATEMENT":
+			if current == nil {
+				last = &ignored
+				continue
+			}
+			if severity == "DETAIL" {
+				current.Detail, last = text, &current.Detail
+			} else {
+				current.Statement, last = text, &current.Statement
+			}
+			continue
+		case "HINT", "CONTEXT", "QUERY", "LOCATION":
+			last = &ignored
+			continue
+		}
+
+		flush()
+		host := p.field(m, "hr")
+		if i := strings.IndexByte(host, '('); i > 0 {
+			host = host[:i]
+		}
+		current = &logEntry{
+			Time:     p.field(m, "mtn"),
+			User:     p.field(m, "u"),
+			Database: p.field(m, "d"),
+			Host:     host,
+			PID:      p.field(m, "p"),
+			SQLState: p.field(m, "e"),
+			Severity: severity,
+			Message:  text,
+		}
+		last = &current.Message
+	}
+	flush()
+}
@@ -0,0 +1,170 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testStderrLog is a stderr log written with log_line_prefix '%m [%p] %q%u@%d '
+const testStderrLog = `2026-01-15 10:00:00.000 UTC [100] LOG:  checkpoint starting: time
+2026-01-15 10:02:30.500 UTC [100] LOG:  checkpoint complete: wrote 1200 buffers (7.3%); 0 WAL file(s) added, 0 removed, 1 recycled; write=149.801 s, sync=0.010 s, total=150.020 s; sync files=12, longest=0.004 s, average=0.001 s; distance=20480 kB, estimate=20480 kB; lsn=0/3000000, redo lsn=0/2000028
+2026-01-15 10:03:00.000 UTC [100] LOG:  checkpoints are occurring too frequently (24 seconds apart)
+2026-01-15 10:03:00.000 UTC [100] HINT:  Consider increasing the configuration parameter "max_wal_size".
+2026-01-15 10:04:00.000 UTC [201] app@shop ERROR:  relation "orderz" does not exist at character 15
+2026-01-15 10:04:00.000 UTC [201] app@shop STATEMENT:  SELECT * FROM orderz
+2026-01-15 10:05:00.000 UTC [202] app@shop ERROR:  relation "custs" does not exist at character 15
+2026-01-15 10:06:00.000 UTC [203] bob@shop FATAL:  password authentication failed for user "bob"
+2026-01-15 10:06:00.000 UTC [203] bob@shop DETAIL:  Connection matched file "pg_hba.conf" line 99: "host all all 0.0.0.0/0 scram-sha-256"
+2026-01-15 10:07:00.000 UTC [204] app@shop LOG:  duration: 2500.125 ms  statement: SELECT count(*)
+	FROM orders
+	WHERE total > 100
+2026-01-15 10:07:30.000 UTC [205] app@shop LOG:  duration: 1200.000 ms  execute S_1: UPDATE orders SET total = 0
+2026-01-15 10:08:00.000 UTC [206] app@shop LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp206.0", size 104857600
+2026-01-15 10:08:00.000 UTC [206] app@shop STATEMENT:  SELECT * FROM orders ORDER BY total
+2026-01-15 10:09:00.000 UTC [207] app@shop LOG:  process 207 still waiting for ShareLock on transaction 750 after 1000.052 ms
+2026-01-15 10:09:00.000 UTC [207] app@shop DETAIL:  Process holding the lock: 208. Wait queue: 207.
+2026-01-15 10:09:01.000 UTC [207] app@shop ERROR:  deadlock detected
+2026-01-15 10:09:01.000 UTC [207] app@shop DETAIL:  Process 207 waits for ShareLock on transaction 750; blocked by process 208.
+2026-01-15 10:10:00.000 UTC [300] LOG:  automatic vacuum of table "shop.public.orders": index scans: 1
+	pages: 0 removed, 1000 remain, 1000 scanned (100.00% of total)
+	system usage: CPU: user: 0.50 s, system: 0.10 s, elapsed: 3.25 s
+2026-01-15 10:20:00.000 UTC [301] LOG:  automatic vacuum of table "shop.public.orders": index scans: 0
+	system usage: CPU: user: 0.10 s, system: 0.00 s, elapsed: 1.75 s
+`
+
+// TestLogAnalyzerStderr verifies the summaries of a stderr log
+func TestLogAnalyzerStderr(t *testing.T) {
+	a := newLogAnalyzer("stderr", "%m [%p] %q%u@%d ", 10)
+	a.add("stderr", []byte(testStderrLog), false)
+	a.add("csvlog", []byte("ignored"), false) // Same messages in another format
+	tables := a.tables()
+
+	summary := make(map[string]string)
+	for _, row := range tables["summary"].Rows {
+		summary[row[0]] = row[1]
+	}
+	for metric, expected := range map[string]string{
+		"log_files": "1", "entries": "13", "first_entry": "2026-01-15 10:00:00.000 UTC",
+		"error": "3", "fatal": "1", "checkpoints": "1", "checkpoints_too_frequent": "1",
+		"autovacuum_runs": "2", "temp_files": "1", "temp_bytes": "104857600",
+		"lock_waits": "1", "deadlocks": "1", "slow_statements": "2", "connection_failures": "1",
+	} {
+		if summary[metric] != expected {
+			t.Errorf("summary %s: expected %q, got %q", metric, expected, summary[metric])
+		}
+	}
+
+	// Messages without SQLSTATE are grouped with their names and numbers replaced
+	errorRows := tables["errors"].Rows
+	if len(errorRows) != 3 || !reflect.DeepEqual(errorRows[0][:3], []string{"ERROR", "", "2"}) {
+		t.Errorf("unexpected errors %q", errorRows)
+	}
+
+	conn := tables["connection_failures"].Rows
+	if len(conn) != 1 || conn[0][0] != "bob" || conn[0][2] != "shop" || conn[0][4] != "1" {
+		t.Errorf("unexpected connection failures %q", conn)
+	}
+
+	checkpoints := tables["checkpoints"].Rows
+	expected := [][]string{
+		{"2026-01-15 10:02:30.500 UTC", "checkpoint", "time", "1200", "149.801", "0.010", "150.020", "20480", ""},
+		{"2026-01-15 10:03:00.000 UTC", "too_frequent", "", "", "", "", "", "", "24"},
+	}
+	if !reflect.DeepEqual(checkpoints, expected) {
+		t.Errorf("expected checkpoints %q, got %q", expected, checkpoints)
+	}
+
+	av := tables["autovacuum"].Rows
+	if len(av) != 1 || !reflect.DeepEqual(av[0], []string{"shop.public.orders", "vacuum", "2", "5.00", "3.25", "2026-01-15 10:20:00.000 UTC"}) {
+		t.Errorf("unexpected autovacuum %q", av)
+	}
+
+	temp := tables["temp_files"].Rows
+	if len(temp) != 1 || temp[0][0] != "SELECT * FROM orders ORDER BY total" {
+		t.Errorf("unexpected temp files %q", temp)
+	}
+
+	locks := tables["lock_waits"].Rows
+	if len(locks) != 2 || locks[0][1] != "waiting" || locks[0][3] != "ShareLock" || locks[0][5] != "1000.052" || locks[1][1] != "deadlock" {
+		t.Errorf("unexpected lock waits %q", locks)
+	}
+
+	slow := tables["slow_statements"].Rows
+	if len(slow) != 2 || slow[0][3] != "2500.125" || slow[0][4] != "SELECT count(*) FROM orders WHERE total > 100" || slow[0][1] != "app" {
+		t.Errorf("unexpected slow statements %q", slow)
+	}
+}
+
+// TestLogPrefixParsing verifies log_line_prefix escapes, padding and journal lines
+func TestLogPrefixParsing(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		journal bool
+		line    string
+		want    logEntry
+	}{
+		{
+			prefix: "%t [%p]: [%l-1] user=%u,db=%d,app=%a,client=%h,%e ",
+			line:   "2026-01-15 10:00:00 CET [42]: [3-1] user=joe,db=app,app=psql vers 16,client=10.0.0.5,42P01 ERROR:  relation \"x\" does not exist",
+			want: logEntry{Time: "2026-01-15 10:00:00 CET", User: "joe", Database: "app", Host: "10.0.0.5", PID: "42",
+				SQLState: "42P01", Severity: "ERROR", Message: `relation "x" does not exist`},
+		},
+		{
+			prefix: "%m %-8u %r ",
+			line:   "2026-01-15 10:00:00.123 +03 joe      10.0.0.5(51234) LOG:  connection authorized",
+			want:   logEntry{Time: "2026-01-15 10:00:00.123 +03", User: "joe", Host: "10.0.0.5", Severity: "LOG", Message: "connection authorized"},
+		},
+		{
+			prefix:  "%m [%p] ",
+			journal: true,
+			line:    "2026-01-15T10:00:00+0000 db1 postgres[42]: 2026-01-15 10:00:00.000 UTC [42] LOG:  database system is ready",
+			want:    logEntry{Time: "2026-01-15 10:00:00.000 UTC", PID: "42", Severity: "LOG", Message: "database system is ready"},
+		},
+		{
+			prefix: "",
+			line:   "FATAL:  the database system is starting up",
+			want:   logEntry{Severity: "FATAL", Message: "the database system is starting up"},
+		},
+	}
+	for _, tt := range tests {
+		var got []logEntry
+		compileLogPrefix(tt.prefix).parse([]byte(tt.line+"\n"), tt.journal, func(e *logEntry) { got = append(got, *e) })
+		if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+			t.Errorf("prefix %q: expected %+v, got %+v", tt.prefix, tt.want, got)
+		}
+	}
+}
+
+// TestLogAnalyzerStructuredFormats verifies csvlog and jsonlog entries
+func TestLogAnalyzerStructuredFormats(t *testing.T) {
+	csvLog := `2026-01-15 10:00:00.000 UTC,"bob","shop",203,"10.0.0.9:50000",65a1.cb,1,"authentication",2026-01-15 10:00:00 UTC,3/1,0,FATAL,28P01,"password authentication failed for user ""bob""","Connection matched file ""pg_hba.conf"" line 99",,,,,,,,"",client backend,,0
+2026-01-15 10:01:00.000 UTC,"app","shop",204,"10.0.0.8:50001",65a1.cc,2,"SELECT",2026-01-15 10:00:00 UTC,3/2,0,LOG,00000,"duration: 1500.000 ms  statement: SELECT 1",,,,,,,,,"psql",client backend,,0
+`
+	jsonLog := `{"timestamp":"2026-01-15 10:00:00.000 UTC","user":"bob","dbname":"shop","pid":203,"remote_host":"10.0.0.9","error_severity":"FATAL","state_code":"28P01","message":"password authentication failed for user \"bob\""}
+{"timestamp":"2026-01-15 10:01:00.000 UTC","user":"app","dbname":"shop","pid":204,"error_severity":"LOG","state_code":"00000","message":"duration: 1500.000 ms  statement: SELECT 1"}
+`
+	for format, data := range map[string]string{"csvlog": csvLog, "jsonlog": jsonLog} {
+		a := newLogAnalyzer(format, "%m [%p] ", 10)
+		a.add(format, []byte(data), false)
+		tables := a.tables()
+
+		conn := tables["connection_failures"].Rows
+		if len(conn) != 1 || !reflect.DeepEqual(conn[0][:5], []string{"bob", "10.0.0.9", "shop", "28P01", "1"}) {
+			t.Errorf("%s: unexpected connection failures %q", format, conn)
+		}
+		slow := tables["slow_statements"].Rows
+		if len(slow) != 1 || !reflect.DeepEqual(slow[0], []string{"2026-01-15 10:01:00.000 UTC", "app", "shop", "1500.000", "SELECT 1"}) {
+			t.Errorf("%s: unexpected slow statements %q", format, slow)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"strconv"
+	"time"
+)
+
+// ManifestPath is the archive entry recording the outcome of every collector
+const ManifestPath = "manifest.tsv"
+
+// Collection result statuses
+const (
+	StatusOK      = "ok"
+	StatusSkipped = "skipped" // Unavailable: command or file missing, no data
+	StatusEmpty   = "empty"   // Ran but produced no output
+	StatusError   = "error"
+)
+
+// CollectionResult records the outcome of one collection task
+type CollectionResult struct {
+	Category    string
+	Name        string
+	ArchivePath string
+	Status      string
+	Duration    time.Duration
+	Error       string
+	// Version of the collector's stable output schema, 0 if it has none
+	SchemaVersion int
+}
+
+// manifestColumns is the header of manifest.tsv
+var manifestColumns = []string{"category", "name", "path", "status", "duration_ms", "error", "schema_version"}
+
+// writeManifest writes manifest.tsv with one row per collection task run.
+func writeManifest(cfg *Config, zipWriter *zip.Writer) error {
+	table := &tsvTable{Columns: manifestColumns}
+	for _, r := range cfg.Results {
+		schemaVersion := ""
+		if r.SchemaVersion > 0 {
+			schemaVersion = strconv.Itoa(r.SchemaVersion)
+		}
+		table.Rows = append(table.Rows, []string{
+			r.Category, r.Name, r.ArchivePath, r.Status,
+			strconv.FormatFloat(float64(r.Duration.Microseconds())/1000, 'f', 1, 64),
+			r.Error, schemaVersion,
+		})
+	}
+
+	w, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     ManifestPath,
+		Method:   DefaultCompressionMethod,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return table.writeTSV(w)
+}
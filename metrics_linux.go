@@ -0,0 +1,146 @@
+//go:build linux
+
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Linux current values exported by radar serve (sorted alphabetically by name).
+// The counter sources of --delta are exported as well.
+var systemGaugeTasks = []SimpleGaugeTask{
+	{
+		Name:     "cgroup",
+		Counters: []string{"cpu_usage_usec"},
+		Snapshot: func(*Config) (*tsvTable, error) {
+			return readCgroupValues("/sys/fs/cgroup")
+		},
+	},
+	{
+		Name:     "loadavg",
+		Snapshot: procCounterSnapshot(func(c ...string) *tsvTable { return parseLoadavg(c[0]) }, "/proc/loadavg"),
+	},
+	{
+		Name:     "meminfo",
+		Keys:     []string{"name"},
+		Snapshot: procCounterSnapshot(func(c ...string) *tsvTable { return parseMeminfo(c[0]) }, "/proc/meminfo"),
+	},
+	{
+		Name:     "pressure",
+		Keys:     []string{"resource", "kind"},
+		Counters: []string{"total_usec"},
+		Snapshot: procCounterSnapshot(parsePressure, "/proc/pressure/cpu", "/proc/pressure/io", "/proc/pressure/memory"),
+	},
+}
+
+// cgroupValueFiles maps cgroup v2 interface files to their exported columns
+var cgroupValueFiles = []struct {
+	file   string
+	column string
+}{
+	{"memory.current", "memory_current_bytes"},
+	{"memory.max", "memory_max_bytes"},
+	{"memory.swap.current", "memory_swap_current_bytes"},
+	{"pids.current", "pids_current"},
+	{"pids.max", "pids_max"},
+}
+
+// readCgroupValues reads the cgroup v2 memory and pids values, and CPU usage
+// from cpu.stat. Limits set to "max" are left empty.
+func readCgroupValues(dir string) (*tsvTable, error) {
+	t := &tsvTable{}
+	var row []string
+	for _, f := range cgroupValueFiles {
+		data, err := os.ReadFile(dir + "/" + f.file)
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			value = ""
+		}
+		t.Columns = append(t.Columns, f.column)
+		row = append(row, value)
+	}
+	if data, err := os.ReadFile(dir + "/cpu.stat"); err == nil {
+		if v, ok := parseNameValues(string(data))["usage_usec"]; ok {
+			t.Columns = append(t.Columns, "cpu_usage_usec")
+			row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	if len(row) == 0 {
+		return nil, NewSkipError("no cgroup v2 values in " + dir)
+	}
+	t.Rows = [][]string{row}
+	return t, nil
+}
+
+// parseLoadavg converts /proc/loadavg into load averages and task counts.
+func parseLoadavg(content string) *tsvTable {
+	t := &tsvTable{Columns: []string{"load1", "load5", "load15", "running", "tasks"}}
+	fields := strings.Fields(content)
+	if len(fields) < 4 {
+		return t
+	}
+	running, tasks, _ := strings.Cut(fields[3], "/")
+	t.Rows = [][]string{{fields[0], fields[1], fields[2], running, tasks}}
+	return t
+}
+
+// parseMeminfo converts /proc/meminfo into a table of values in bytes.
+func parseMeminfo(content string) *tsvTable {
+	t := &tsvTable{Columns: []string{"name", "bytes"}}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 2 && fields[2] == "kB" {
+			v *= 1024
+		}
+		t.Rows = append(t.Rows, []string{strings.TrimSuffix(fields[0], ":"), strconv.FormatFloat(v, 'f', -1, 64)})
+	}
+	return t
+}
+
+// parsePressure converts the /proc/pressure cpu, io and memory files, in that
+// order, into a table keyed on resource and kind (some or full).
+func parsePressure(contents ...string) *tsvTable {
+	t := &tsvTable{Columns: []string{"resource", "kind", "avg10", "avg60", "avg300", "total_usec"}}
+	resources := []string{"cpu", "io", "memory"}
+	for i, content := range contents {
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 5 {
+				continue
+			}
+			row := []string{resources[i], fields[0], "", "", "", ""}
+			for _, f := range fields[1:] {
+				name, value, _ := strings.Cut(f, "=")
+				if c := t.columnIndex(name); c >= 0 {
+					row[c] = value
+				} else if name == "total" {
+					row[5] = value
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+	}
+	return t
+}
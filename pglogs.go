@@ -0,0 +1,364 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Server log collection defaults (--log-window, --log-max-size)
+const (
+	DefaultLogWindow  = 24 * time.Hour
+	DefaultLogMaxSize = 20 // MB from the end of each log file
+)
+
+// Archive locations of the server logs
+const (
+	LogArchiveDir      = "postgresql/logs"
+	JournalArchivePath = LogArchiveDir + "/journal.log"
+)
+
+// JournalUnitPattern matches the systemd units of PostgreSQL packages
+// (postgresql.service, postgresql@16-main.service, postgresql-16.service)
+const JournalUnitPattern = "postgresql*"
+
+// logFormats are the log_destination formats written to files, with the
+// extension the logging collector gives their files
+var logFormats = []struct{ name, ext string }{
+	{"stderr", ""},
+	{"csvlog", ".csv"},
+	{"jsonlog", ".json"}, // PG15+
+}
+
+// logSettings are the server settings that locate the log files
+type logSettings struct {
+	DataDirectory    string
+	LogDirectory     string // Absolute
+	LogFilename      string
+	LogLinePrefix    string
+	LoggingCollector bool
+	CurrentFiles     []string // Absolute paths reported by pg_current_logfile()
+}
+
+// generateLogTasks locates the server log files modified within --log-window
+// and returns a task per file, followed by the tasks writing their analysis.
+// Servers that do not run the logging collector log to stderr, which is read
+// from journald instead. The logs of a remote server are not on this machine,
+// so their collection is skipped.
+func generateLogTasks(cfg *Config) ([]CollectionTask, error) {
+	if cfg.LogWindow <= 0 {
+		return nil, nil
+	}
+	if !isLocalHost(cfg.Host) {
+		reason := fmt.Sprintf("PostgreSQL host %s is not this machine, its server logs cannot be read", cfg.Host)
+		return []CollectionTask{{
+			Category:    "postgresql",
+			Name:        "server_log",
+			ArchivePath: LogArchiveDir,
+			Collector: func(*Config, io.Writer) error {
+				return NewSkipError(reason)
+			},
+		}}, nil
+	}
+	if cfg.DB == nil {
+		return nil, fmt.Errorf("PostgreSQL not initialized")
+	}
+
+	settings, err := queryLogSettings(cfg.DB, cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.LoggingCollector && len(settings.CurrentFiles) == 0 {
+		analyzer := newLogAnalyzer("stderr", settings.LogLinePrefix, cfg.TopN)
+		return append([]CollectionTask{{
+			Category:    "postgresql",
+			Name:        "server_log_journal",
+			ArchivePath: JournalArchivePath,
+			Collector:   journalCollector(analyzer),
+		}}, logAnalysisTasks(analyzer)...), nil
+	}
+
+	files, err := findLogFiles(settings, cfg.LogWindow, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// With several log destinations the files repeat the same messages;
+	// the most structured format is analyzed
+	format := "stderr"
+	for _, path := range files {
+		if f := logFormatOf(path); f == "jsonlog" || (f == "csvlog" && format == "stderr") {
+			format = f
+		}
+	}
+	analyzer := newLogAnalyzer(format, settings.LogLinePrefix, cfg.TopN)
+
+	tasks := make([]CollectionTask, 0, len(files)+len(logAnalysisFiles))
+	for _, path := range files {
+		tasks = append(tasks, CollectionTask{
+			Category:    "postgresql",
+			Name:        "server_log/" + filepath.Base(path),
+			ArchivePath: LogArchiveDir + "/" + filepath.Base(path),
+			Collector:   logFileCollector(path, analyzer),
+		})
+	}
+	return append(tasks, logAnalysisTasks(analyzer)...), nil
+}
+
+// queryLogSettings reads the logging settings and the current log files.
+// dataDir, from --data-dir, is used when data_directory cannot be read.
+func queryLogSettings(db *sql.DB, dataDir string) (*logSettings, error) {
+	var s logSettings
+	var collector string
+	err := db.QueryRow(`SELECT current_setting('log_directory'),
+       current_setting('log_filename'),
+       current_setting('log_line_prefix'),
+       current_setting('logging_collector')`).Scan(&s.LogDirectory, &s.LogFilename, &s.LogLinePrefix, &collector)
+	if err != nil {
+		return nil, fmt.Errorf("reading log settings: %w", err)
+	}
+	s.LoggingCollector = collector == "on"
+
+	// data_directory needs superuser or pg_read_all_settings
+	if err := db.QueryRow("SHOW data_directory").Scan(&s.DataDirectory); err != nil {
+		if dataDir == "" {
+			return nil, fmt.Errorf("detecting data directory: %w", err)
+		}
+		s.DataDirectory = dataDir
+	}
+	if !filepath.IsAbs(s.LogDirectory) {
+		s.LogDirectory = filepath.Join(s.DataDirectory, s.LogDirectory)
+	}
+
+	// pg_current_logfile() rejects formats the server does not know, and
+	// is NULL for formats not in log_destination
+	for _, f := range logFormats {
+		var path sql.NullString
+		if err := db.QueryRow("SELECT pg_current_logfile($1)", f.name).Scan(&path); err != nil || !path.Valid {
+			continue
+		}
+		if !filepath.IsAbs(path.String) {
+			path.String = filepath.Join(s.DataDirectory, path.String)
+		}
+		s.CurrentFiles = append(s.CurrentFiles, path.String)
+	}
+	return &s, nil
+}
+
+// findLogFiles lists the files of the log directory that match log_filename
+// in any log format and were modified within window, oldest first. The
+// current log files are always included.
+func findLogFiles(s *logSettings, window time.Duration, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(s.LogDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("reading log directory: %w", err)
+	}
+
+	patterns := logFilenameGlobs(s.LogFilename)
+	current := make(map[string]bool)
+	for _, path := range s.CurrentFiles {
+		current[filepath.Clean(path)] = true
+	}
+
+	type logFile struct {
+		path     string
+		modified time.Time
+	}
+	var files []logFile
+	for _, entry := range entries {
+		path := filepath.Join(s.LogDirectory, entry.Name())
+		if !entry.Type().IsRegular() || !(current[path] || matchesAny(patterns, entry.Name())) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if current[path] || now.Sub(info.ModTime()) <= window {
+			files = append(files, logFile{path, info.ModTime()})
+			delete(current, path)
+		}
+	}
+	// Current files outside the log directory (log_directory changed on reload)
+	for path := range current {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, logFile{path, info.ModTime()})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modified.Equal(files[j].modified) {
+			return files[i].modified.Before(files[j].modified)
+		}
+		return files[i].path < files[j].path
+	})
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// logFilenameGlobs turns the strftime pattern of log_filename into glob
+// patterns for the file of each log format: csvlog and jsonlog replace a
+// .log extension with their own, or append it.
+func logFilenameGlobs(logFilename string) []string {
+	var b strings.Builder
+	for i := 0; i < len(logFilename); i++ {
+		c := logFilename[i]
+		switch {
+		case c == '%' && i+1 < len(logFilename) && logFilename[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case c == '%':
+			b.WriteByte('*')
+			i++
+		case strings.IndexByte(`*?[\`, c) >= 0:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	glob := b.String()
+
+	globs := make([]string, 0, len(logFormats))
+	for _, f := range logFormats {
+		if f.ext == "" {
+			globs = append(globs, glob)
+		} else {
+			globs = append(globs, strings.TrimSuffix(glob, ".log")+f.ext)
+		}
+	}
+	return globs
+}
+
+// matchesAny reports whether name matches one of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// logMaxBytes returns the --log-max-size cap in bytes.
+func logMaxBytes(cfg *Config) int64 {
+	size := cfg.LogMaxSize
+	if size < 1 {
+		size = DefaultLogMaxSize
+	}
+	return int64(size) << 20
+}
+
+// logFileCollector creates a collector that copies the end of a log file,
+// at most --log-max-size, starting at a line boundary, and passes the copy
+// to the analyzer.
+func logFileCollector(path string, analyzer *logAnalyzer) func(*Config, io.Writer) error {
+	return func(cfg *Config, w io.Writer) error {
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return NewSkipError(fmt.Sprintf("file not found: %s", path))
+			}
+			return fmt.Errorf("read failed: %w", err)
+		}
+		defer closeErrCheck(f, "log file")
+
+		var buf bytes.Buffer
+		if err := copyTail(f, logMaxBytes(cfg), io.MultiWriter(w, &buf)); err != nil {
+			return err
+		}
+		analyzer.add(logFormatOf(path), buf.Bytes(), false)
+		return nil
+	}
+}
+
+// copyTail copies the last maxBytes of f, from the first complete line, up
+// to the size f had when the copy started.
+func copyTail(f *os.File, maxBytes int64, w io.Writer) error {
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("read failed: %w", err)
+	}
+	size := info.Size()
+	offset := size - maxBytes
+	if offset <= 0 {
+		_, err = io.CopyN(w, f, size)
+		return err
+	}
+
+	// Skip the partial line the cap cuts into
+	r := bufio.NewReader(io.NewSectionReader(f, offset-1, size-offset+1))
+	if _, err := r.ReadBytes('\n'); err != nil {
+		return nil // No line starts within the cap
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// tailLines returns the last maxBytes of data, from the first complete line.
+func tailLines(data []byte, maxBytes int64) []byte {
+	if int64(len(data)) <= maxBytes {
+		return data
+	}
+	cut := int64(len(data)) - maxBytes
+	if data[cut-1] == '\n' {
+		return data[cut:]
+	}
+	i := bytes.IndexByte(data[cut:], '\n')
+	if i < 0 {
+		return nil
+	}
+	return data[cut+int64(i)+1:]
+}
+
+// journalCollector creates a collector that reads the --log-window of the
+// PostgreSQL units from journald, capped at --log-max-size, and passes it to
+// the analyzer.
+func journalCollector(analyzer *logAnalyzer) func(*Config, io.Writer) error {
+	return func(cfg *Config, w io.Writer) error {
+		return collectJournal(cfg, analyzer, w)
+	}
+}
+
+// collectJournal runs journalctl for journalCollector.
+func collectJournal(cfg *Config, analyzer *logAnalyzer, w io.Writer) error {
+	window := cfg.LogWindow
+	if window <= 0 {
+		window = DefaultLogWindow
+	}
+	data, err := execCommand("journalctl", "--unit", JournalUnitPattern,
+		"--since", fmt.Sprintf("-%ds", int64(window.Seconds())),
+		"--no-pager", "--output", "short-iso")
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-- No entries --")) {
+		return NewSkipError("no journal entries for " + JournalUnitPattern)
+	}
+	data = tailLines(data, logMaxBytes(cfg))
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	analyzer.add("stderr", data, true)
+	return nil
+}
@@ -0,0 +1,161 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestLogFilenameGlobs verifies log_filename patterns match the files of every log format
+func TestLogFilenameGlobs(t *testing.T) {
+	globs := logFilenameGlobs("postgresql-%Y-%m-%d_%H%M%S.log")
+	expected := []string{"postgresql-*-*-*_***.log", "postgresql-*-*-*_***.csv", "postgresql-*-*-*_***.json"}
+	if !reflect.DeepEqual(globs, expected) {
+		t.Errorf("expected %q, got %q", expected, globs)
+	}
+
+	for name, want := range map[string]bool{
+		"postgresql-2026-01-02_030405.log":  true,
+		"postgresql-2026-01-02_030405.csv":  true,
+		"postgresql-2026-01-02_030405.json": true,
+		"postgresql.conf":                   false,
+	} {
+		if got := matchesAny(globs, name); got != want {
+			t.Errorf("matchesAny(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	// Literal % and glob characters, and a name without .log
+	if got := logFilenameGlobs("pg[%%]-%a"); got[0] != `pg\[%]-*` || got[1] != `pg\[%]-*.csv` {
+		t.Errorf("unexpected globs %q", got)
+	}
+}
+
+// TestFindLogFiles verifies the time window, the current files and the ordering
+func TestFindLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := map[string]time.Duration{
+		"postgresql-Mon.log": 50 * time.Hour, // Outside the window
+		"postgresql-Tue.log": 20 * time.Hour,
+		"postgresql-Tue.csv": 20 * time.Hour,
+		"postgresql-Wed.log": time.Hour,
+		"postgresql-Thu.log": 72 * time.Hour, // Current, although old
+		"other.txt":          time.Hour,
+	}
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &logSettings{
+		LogDirectory: dir,
+		LogFilename:  "postgresql-%a.log",
+		CurrentFiles: []string{filepath.Join(dir, "postgresql-Thu.log")},
+	}
+	got, err := findLogFiles(s, 24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("findLogFiles failed: %v", err)
+	}
+	var names []string
+	for _, path := range got {
+		names = append(names, filepath.Base(path))
+	}
+	expected := []string{"postgresql-Thu.log", "postgresql-Tue.csv", "postgresql-Tue.log", "postgresql-Wed.log"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %q, got %q", expected, names)
+	}
+}
+
+// TestGenerateLogTasksRemote verifies the logs of a remote server are skipped
+// without looking for log files
+func TestGenerateLogTasksRemote(t *testing.T) {
+	tasks, err := generateLogTasks(&Config{Host: "192.0.2.1", LogWindow: time.Hour})
+	if err != nil {
+		t.Fatalf("generateLogTasks failed: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("expected a single skipped task, got %d", len(tasks))
+	}
+	var skipErr SkipError
+	if err := tasks[0].Collector(&Config{}, &bytes.Buffer{}); !errors.As(err, &skipErr) {
+		t.Errorf("expected skip, got %v", err)
+	}
+}
+
+// TestQueryLogSettings verifies relative log paths resolve against the data directory
+func TestQueryLogSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	mock.ExpectQuery("SELECT current_setting").WillReturnRows(
+		sqlmock.NewRows([]string{"log_directory", "log_filename", "log_line_prefix", "logging_collector"}).AddRow("log", "postgresql-%a.log", "%m [%p] ", "on"))
+	mock.ExpectQuery("SHOW data_directory").WillReturnRows(sqlmock.NewRows([]string{"data_directory"}).AddRow("/var/lib/pgsql/data"))
+	mock.ExpectQuery("pg_current_logfile").WithArgs("stderr").WillReturnRows(sqlmock.NewRows([]string{"f"}).AddRow("log/postgresql-Thu.log"))
+	mock.ExpectQuery("pg_current_logfile").WithArgs("csvlog").WillReturnRows(sqlmock.NewRows([]string{"f"}).AddRow(nil))
+	mock.ExpectQuery("pg_current_logfile").WithArgs("jsonlog").WillReturnError(errors.New(`log format "jsonlog" is not supported`))
+
+	s, err := queryLogSettings(db, "")
+	if err != nil {
+		t.Fatalf("queryLogSettings failed: %v", err)
+	}
+	if s.LogDirectory != "/var/lib/pgsql/data/log" || !s.LoggingCollector {
+		t.Errorf("unexpected settings %+v", s)
+	}
+	if !reflect.DeepEqual(s.CurrentFiles, []string{"/var/lib/pgsql/data/log/postgresql-Thu.log"}) {
+		t.Errorf("unexpected current files %q", s.CurrentFiles)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLogFileCollectorCap verifies the size cap keeps whole lines from the end of the file
+func TestLogFileCollectorCap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "postgresql.log")
+	line := strings.Repeat("x", 99) + "\n"
+	if err := os.WriteFile(path, []byte(strings.Repeat(line, 20000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := logFileCollector(path, newLogAnalyzer("stderr", "", 0))(&Config{LogMaxSize: 1}, &buf); err != nil {
+		t.Fatalf("collector failed: %v", err)
+	}
+	// 1 MB cuts into a line, which is dropped
+	if buf.Len() != (1<<20)/100*100 || !strings.HasPrefix(buf.String(), line) {
+		t.Errorf("expected %d bytes of whole lines, got %d", (1<<20)/100*100, buf.Len())
+	}
+
+	if got := string(tailLines([]byte("one\ntwo\nthree\n"), 8)); got != "three\n" {
+		t.Errorf("expected the last whole line, got %q", got)
+	}
+	if got := string(tailLines([]byte("one\ntwo\n"), 4)); got != "two\n" {
+		t.Errorf("expected a line starting at the cap, got %q", got)
+	}
+}
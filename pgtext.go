@@ -0,0 +1,282 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// errNotPgx reports a connection of another driver than pgx
+var errNotPgx = errors.New("not a pgx connection")
+
+// textRows is a result set whose values are rendered in PostgreSQL's text
+// output format, as shown by psql and written by COPY. An invalid
+// sql.NullString is NULL.
+type textRows interface {
+	Columns() []string
+	ColumnTypes() []string // Upper-case type names, or the OID if unknown
+	Next() bool
+	Values() ([]sql.NullString, error)
+	Err() error
+}
+
+// queryTextRows runs query and passes its result set to fn. On pgx
+// connections the query uses the simple protocol, so that the server renders
+// every value as text; other drivers go through database/sql and have their
+// values rendered by pgText.
+func queryTextRows(db *sql.DB, query string, fn func(textRows) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return pgQueryError(err)
+	}
+	defer closeErrCheck(conn, "database connection")
+
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNotPgx
+		}
+		rows, err := c.Conn().Query(ctx, query, pgx.QueryExecModeSimpleProtocol)
+		if err != nil {
+			return pgQueryError(err)
+		}
+		defer rows.Close()
+
+		// Errors of the simple protocol arrive with the first row
+		r := &pgxTextRows{rows: rows, conn: c.Conn(), pending: rows.Next()}
+		if !r.pending && rows.Err() != nil {
+			return pgQueryError(rows.Err())
+		}
+		return fn(r)
+	})
+	if !errors.Is(err, errNotPgx) {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return pgQueryError(err)
+	}
+	defer closeErrCheck(rows, "query rows")
+	r, err := newSQLTextRows(rows)
+	if err != nil {
+		return err
+	}
+	return fn(r)
+}
+
+// pgQueryError turns errors about objects that are not installed into skips.
+func pgQueryError(err error) error {
+	if isPGUnavailableError(err) {
+		return NewSkipError(err.Error())
+	}
+	return err
+}
+
+// pgxTextRows reads the raw text values of a simple protocol result set
+type pgxTextRows struct {
+	rows    pgx.Rows
+	conn    *pgx.Conn
+	pending bool // First row already fetched
+}
+
+// Columns returns the column names.
+func (r *pgxTextRows) Columns() []string {
+	fields := r.rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	return columns
+}
+
+// ColumnTypes returns the column type names as database/sql reports them.
+func (r *pgxTextRows) ColumnTypes() []string {
+	fields := r.rows.FieldDescriptions()
+	types := make([]string, len(fields))
+	for i, f := range fields {
+		if t, ok := r.conn.TypeMap().TypeForOID(f.DataTypeOID); ok {
+			types[i] = strings.ToUpper(t.Name)
+		} else {
+			types[i] = strconv.FormatUint(uint64(f.DataTypeOID), 10)
+		}
+	}
+	return types
+}
+
+// Next advances to the next row.
+func (r *pgxTextRows) Next() bool {
+	if r.pending {
+		r.pending = false
+		return true
+	}
+	return r.rows.Next()
+}
+
+// Values returns the current row as sent by the server.
+func (r *pgxTextRows) Values() ([]sql.NullString, error) {
+	raw := r.rows.RawValues()
+	values := make([]sql.NullString, len(raw))
+	for i, v := range raw {
+		if v != nil {
+			values[i] = sql.NullString{String: string(v), Valid: true}
+		}
+	}
+	return values, nil
+}
+
+// Err returns the error, if any, that ended the iteration.
+func (r *pgxTextRows) Err() error {
+	return r.rows.Err()
+}
+
+// sqlTextRows renders the values scanned by database/sql as text
+type sqlTextRows struct {
+	rows      *sql.Rows
+	columns   []string
+	types     []string
+	values    []interface{}
+	valuePtrs []interface{}
+}
+
+// newSQLTextRows wraps a database/sql result set.
+func newSQLTextRows(rows *sql.Rows) (*sqlTextRows, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("getting columns: %w", err)
+	}
+	r := &sqlTextRows{
+		rows:      rows,
+		columns:   columns,
+		types:     make([]string, len(columns)),
+		values:    make([]interface{}, len(columns)),
+		valuePtrs: make([]interface{}, len(columns)),
+	}
+	if columnTypes, err := rows.ColumnTypes(); err == nil {
+		for i, ct := range columnTypes {
+			r.types[i] = ct.DatabaseTypeName()
+		}
+	}
+	for i := range r.values {
+		r.valuePtrs[i] = &r.values[i]
+	}
+	return r, nil
+}
+
+// Columns returns the column names.
+func (r *sqlTextRows) Columns() []string { return r.columns }
+
+// ColumnTypes returns the column type names reported by the driver.
+func (r *sqlTextRows) ColumnTypes() []string { return r.types }
+
+// Next advances to the next row.
+func (r *sqlTextRows) Next() bool { return r.rows.Next() }
+
+// Err returns the error, if any, that ended the iteration.
+func (r *sqlTextRows) Err() error { return r.rows.Err() }
+
+// Values scans the current row and renders it as text.
+func (r *sqlTextRows) Values() ([]sql.NullString, error) {
+	if err := r.rows.Scan(r.valuePtrs...); err != nil {
+		return nil, fmt.Errorf("scanning row: %w", err)
+	}
+	values := make([]sql.NullString, len(r.values))
+	for i, v := range r.values {
+		if v != nil {
+			values[i] = sql.NullString{String: pgText(v, r.types[i]), Valid: true}
+		}
+	}
+	return values, nil
+}
+
+// pgText renders a value scanned by database/sql the way PostgreSQL's text
+// output would, for the common types: booleans as t/f, bytea as hex and
+// timestamps in the ISO DateStyle.
+func pgText(val interface{}, typeName string) string {
+	switch v := val.(type) {
+	case bool:
+		if v {
+			return "t"
+		}
+		return "f"
+	case []byte:
+		if typeName == "BYTEA" {
+			return `\x` + hex.EncodeToString(v)
+		}
+		return string(v)
+	case string:
+		return v
+	case float64:
+		return pgFloatText(v, 64)
+	case float32:
+		return pgFloatText(float64(v), 32)
+	case time.Time:
+		switch typeName {
+		case "DATE":
+			return v.Format("2006-01-02")
+		case "TIMESTAMP":
+			return v.Format("2006-01-02 15:04:05.999999")
+		}
+		return v.Format("2006-01-02 15:04:05.999999") + pgZoneOffset(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// pgFloatText renders a float in the shortest exact form, switching to
+// exponent notation where PostgreSQL does (exponents below -4 or from the
+// type's decimal digits upward).
+func pgFloatText(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	digits := 15 // DBL_DIG
+	if bitSize == 32 {
+		digits = 6 // FLT_DIG
+	}
+	s := strconv.FormatFloat(f, 'e', -1, bitSize)
+	exp, _ := strconv.Atoi(s[strings.IndexByte(s, 'e')+1:])
+	if exp < -4 || exp >= digits {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+// pgZoneOffset renders a UTC offset as PostgreSQL does: hours, with minutes
+// and seconds only when not zero.
+func pgZoneOffset(t time.Time) string {
+	_, offset := t.Zone()
+	switch {
+	case offset%3600 == 0:
+		return t.Format("-07")
+	case offset%60 == 0:
+		return t.Format("-07:00")
+	default:
+		return t.Format("-07:00:00")
+	}
+}
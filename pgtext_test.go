@@ -0,0 +1,79 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestPGText verifies database/sql values render as PostgreSQL text output
+func TestPGText(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.UTC)
+	india := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("IST", 5*3600+1800))
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		typeName string
+		expected string
+	}{
+		{"true", true, "BOOL", "t"},
+		{"false", false, "BOOL", "f"},
+		{"timestamptz", ts, "TIMESTAMPTZ", "2026-01-02 03:04:05.123+00"},
+		{"timestamptz half hour offset", india, "TIMESTAMPTZ", "2026-01-02 03:04:05+05:30"},
+		{"timestamp", ts, "TIMESTAMP", "2026-01-02 03:04:05.123"},
+		{"date", ts, "DATE", "2026-01-02"},
+		{"bytea", []byte{0xde, 0xad}, "BYTEA", `\xdead`},
+		{"text bytes", []byte("abc"), "TEXT", "abc"},
+		{"float", 0.1, "FLOAT8", "0.1"},
+		{"large float", 123456789012.5, "FLOAT8", "123456789012.5"},
+		{"exponent float", 1e20, "FLOAT8", "1e+20"},
+		{"small float", 0.00001, "FLOAT8", "1e-05"},
+		{"float4", float32(1.5), "FLOAT4", "1.5"},
+		{"nan", math.NaN(), "FLOAT8", "NaN"},
+		{"infinity", math.Inf(-1), "FLOAT8", "-Infinity"},
+		{"integer", int64(42), "INT8", "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pgText(tt.value, tt.typeName); got != tt.expected {
+				t.Errorf("pgText(%v, %s) = %q, want %q", tt.value, tt.typeName, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPGQueryTextFallback verifies non-pgx drivers render booleans and timestamps as psql does
+func TestPGQueryTextFallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("pending_restart").OfType("BOOL", false),
+		sqlmock.NewColumn("backend_start").OfType("TIMESTAMPTZ", time.Time{}),
+	).AddRow(true, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	var buf bytes.Buffer
+	if err := pgQueryToTSV(db, "SELECT", &buf, tsvOptions{Header: true}); err != nil {
+		t.Fatalf("pgQueryToTSV failed: %v", err)
+	}
+	expected := "pending_restart\tbackend_start\nt\t2026-01-02 03:04:05+00\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -39,6 +39,66 @@ func isPGUnavailableError(err error) bool {
 	return false
 }
 
+// Server roles, detected with pg_is_in_recovery()
+const (
+	RolePrimary = "primary"
+	RoleStandby = "standby"
+)
+
+// ServerRolePath records the role the collectors were chosen for
+const ServerRolePath = "postgresql/server_role.tsv"
+
+// detectServerRole returns RoleStandby for a server in recovery, else
+// RolePrimary.
+func detectServerRole(db *sql.DB) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("PostgreSQL not initialized")
+	}
+	var inRecovery bool
+	if err := db.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return "", fmt.Errorf("querying pg_is_in_recovery(): %w", err)
+	}
+	if inRecovery {
+		return RoleStandby, nil
+	}
+	return RolePrimary, nil
+}
+
+// runsOnRole reports whether a task for taskRole runs on a server of role.
+// Role-specific tasks are left out when the role is unknown.
+func runsOnRole(taskRole, role string) bool {
+	return taskRole == "" || taskRole == role
+}
+
+// forServerRole drops the tasks of the other server role, including the
+// other variant of collectors that have one per role.
+func forServerRole(tasks []CollectionTask, role string) []CollectionTask {
+	var result []CollectionTask
+	for _, task := range tasks {
+		if runsOnRole(task.Role, role) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// serverRoleTask records the detected server role in the archive.
+func serverRoleTask(role string) CollectionTask {
+	return CollectionTask{
+		Category:    "postgresql",
+		Name:        "server_role",
+		ArchivePath: ServerRolePath,
+		Collector: func(cfg *Config, w io.Writer) error {
+			inRecovery := "f"
+			if role == RoleStandby {
+				inRecovery = "t"
+			}
+			_, err := fmt.Fprintf(w, "role\tin_recovery\n%s\t%s\n", role, inRecovery)
+			return err
+		},
+	}
+}
+
 // postgresConfigFileTasks defines tasks for collecting PostgreSQL configuration files (sorted alphabetically by name)
 var postgresConfigFileTasks = []SimpleConfigFileTask{
 	{
@@ -81,6 +141,12 @@ func getPostgreSQLTasks(db *sql.DB) []CollectionTask {
 	// Build config file tasks
 	tasks = append(tasks, buildConfigFileTasks("postgresql", postgresConfigFileTasks, db)...)
 
+	// Top statements from wherever pg_stat_statements is installed
+	tasks = append(tasks, getStatementsTasks(db)...)
+
+	// Join backend processes to pg_stat_activity (Linux only)
+	tasks = append(tasks, getBackendOSTasks(db)...)
+
 	return tasks
 }
 
@@ -109,8 +175,9 @@ func collectPGConfigFile(db *sql.DB, cfg *Config, filename string, w io.Writer)
 	return err
 }
 
-// generateDatabaseTasks creates per-database collection tasks
-func generateDatabaseTasks(db *sql.DB) ([]CollectionTask, error) {
+// generateDatabaseTasks creates per-database collection tasks, including the
+// pgstattuple_approx measurements when exactBloat is set
+func generateDatabaseTasks(db *sql.DB, exactBloat bool) ([]CollectionTask, error) {
 	if db == nil {
 		return nil, fmt.Errorf("PostgreSQL not initialized")
 	}
@@ -142,6 +209,9 @@ func generateDatabaseTasks(db *sql.DB) ([]CollectionTask, error) {
 	// Generate tasks for each database
 	var tasks []CollectionTask
 	allDBTasks := append(perDatabaseQueryTasks, pgStatvizQueryTasks...)
+	if exactBloat {
+		allDBTasks = append(allDBTasks, exactBloatQueryTasks...)
+	}
 
 	for _, dbname := range databases {
 		// Capture loop variables for closure
@@ -151,14 +221,12 @@ func generateDatabaseTasks(db *sql.DB) ([]CollectionTask, error) {
 			// Capture loop variable for closure
 			td := taskDef
 
-			tasks = append(tasks, CollectionTask{
-				Category:    "database",
-				Name:        fmt.Sprintf("%s/%s", dbName, td.Name),
-				ArchivePath: fmt.Sprintf(td.ArchivePath, dbName),
-				Collector: func(cfg *Config, w io.Writer) error {
-					return execPGQueryOnDB(dbName, cfg, td.Query, w)
-				},
-			})
+			tasks = append(tasks, queryTasks("database", fmt.Sprintf("%s/%s", dbName, td.Name), fmt.Sprintf(td.ArchivePath, dbName), td.Schema,
+				func(schema *OutputSchema) func(*Config, io.Writer) error {
+					return func(cfg *Config, w io.Writer) error {
+						return execPGQueryOnDB(dbName, cfg, td.Query, schema, w)
+					}
+				})...)
 		}
 	}
 
@@ -166,23 +234,14 @@ func generateDatabaseTasks(db *sql.DB) ([]CollectionTask, error) {
 }
 
 // execPGQueryOnDB executes a query on a specific database
-func execPGQueryOnDB(dbname string, cfg *Config, query string, w io.Writer) error {
+func execPGQueryOnDB(dbname string, cfg *Config, query string, schema *OutputSchema, w io.Writer) error {
 	db, err := sql.Open("pgx", cfg.ConnectionString(dbname))
 	if err != nil {
 		return fmt.Errorf("connecting to %s: %w", dbname, err)
 	}
 	defer closeErrCheck(db, "database connection")
 
-	rows, err := db.Query(query)
-	if err != nil {
-		if isPGUnavailableError(err) {
-			return NewSkipError(err.Error())
-		}
-		return fmt.Errorf("query failed: %w", err)
-	}
-	defer closeErrCheck(rows, "query rows")
-
-	return rowsToTSV(rows, w)
+	return pgQueryToTSV(db, expandQuery(query, cfg), w, tsvOptions{Header: true, Format: cfg.QueryFormat, Schema: schema})
 }
 
 // printSummary logs the archive filename, size, and collector count.
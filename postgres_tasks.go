@@ -20,6 +20,9 @@ type SimpleQueryTask struct {
 	Name        string
 	ArchivePath string
 	Query       string
+	Volatile    bool          // Point-in-time view, repeatedly sampled with --samples
+	Schema      *OutputSchema // Stable column list of a SELECT * query
+	Role        string        // Server role the query runs on (RolePrimary, RoleStandby); empty for both
 }
 
 // SimpleConfigFileTask defines a PostgreSQL config file collection
@@ -35,6 +38,8 @@ var postgresQueryTasks = []SimpleQueryTask{
 		Name:        "activity",
 		ArchivePath: "postgresql/running_activity.tsv",
 		Query:       "SELECT * FROM pg_stat_activity ORDER BY pid",
+		Volatile:    true,
+		Schema:      activitySchema,
 	},
 	{
 		Name:        "archiver",
@@ -50,6 +55,7 @@ var postgresQueryTasks = []SimpleQueryTask{
 		Name:        "bgwriter",
 		ArchivePath: "postgresql/bgwriter.tsv",
 		Query:       "SELECT * FROM pg_stat_bgwriter",
+		Schema:      bgwriterSchema,
 	},
 	{
 		Name:        "blocking_locks",
@@ -76,11 +82,13 @@ JOIN pg_catalog.pg_locks blocking_locks
     AND blocking_locks.pid != blocked_locks.pid
 JOIN pg_catalog.pg_stat_activity blocking_activity ON blocking_activity.pid = blocking_locks.pid
 WHERE NOT blocked_locks.granted`,
+		Volatile: true,
 	},
 	{
 		Name:        "checkpointer",
 		ArchivePath: "postgresql/checkpointer.tsv",
 		Query:       "SELECT * FROM pg_stat_checkpointer",
+		Schema:      checkpointerSchema,
 	},
 	{
 		Name:        "configuration",
@@ -91,16 +99,18 @@ WHERE NOT blocked_locks.granted`,
 		Name:        "connection_summary",
 		ArchivePath: "postgresql/connection_summary.tsv",
 		Query:       "SELECT state, wait_event_type, count(*) FROM pg_stat_activity GROUP BY state, wait_event_type ORDER BY count(*) DESC",
+		Volatile:    true,
 	},
 	{
 		Name:        "database_conflicts",
 		ArchivePath: "postgresql/database_conflicts.tsv",
 		Query:       "SELECT * FROM pg_stat_database_conflicts ORDER BY datname",
+		Schema:      databaseConflictsSchema,
 	},
 	{
 		Name:        "database_sizes",
 		ArchivePath: "postgresql/database_sizes.tsv",
-		Query:       "SELECT datname, pg_size_pretty(pg_database_size(datname)) AS size FROM pg_database WHERE datallowconn ORDER BY pg_database_size(datname) DESC",
+		Query:       "SELECT datname, pg_size_pretty(pg_database_size(datname)) AS size, pg_database_size(datname) AS size_bytes FROM pg_database WHERE datallowconn ORDER BY size_bytes DESC",
 	},
 	{
 		Name:        "databases",
@@ -141,6 +151,7 @@ WHERE NOT blocked_locks.granted`,
 		Name:        "pg_hba_file_rules",
 		ArchivePath: "postgresql/pg_hba_file_rules.tsv",
 		Query:       "SELECT * FROM pg_hba_file_rules ORDER BY line_number",
+		Schema:      hbaFileRulesSchema,
 	},
 	{
 		Name:        "postmaster_start_time",
@@ -152,25 +163,144 @@ WHERE NOT blocked_locks.granted`,
 		ArchivePath: "postgresql/prepared_xacts.tsv",
 		Query:       "SELECT * FROM pg_prepared_xacts ORDER BY prepared",
 	},
+	{
+		Name:        "recovery_conflicts",
+		ArchivePath: "postgresql/recovery_conflicts.tsv",
+		Query: `SELECT c.datname,
+       d.conflicts AS total_conflicts,
+       c.confl_tablespace,
+       c.confl_lock,
+       c.confl_snapshot,
+       c.confl_bufferpin,
+       c.confl_deadlock,
+       (to_jsonb(c) ->> 'confl_active_logicalslot')::bigint AS confl_active_logicalslot,
+       d.stats_reset
+FROM pg_stat_database_conflicts c
+JOIN pg_stat_database d ON d.datid = c.datid
+ORDER BY d.conflicts DESC, c.datname`,
+		Role: RoleStandby,
+	},
+	{
+		Name:        "recovery_status",
+		ArchivePath: "postgresql/recovery_status.tsv",
+		Query: `SELECT pg_last_wal_receive_lsn() AS last_wal_receive_lsn,
+       pg_last_wal_replay_lsn() AS last_wal_replay_lsn,
+       pg_last_xact_replay_timestamp() AS last_xact_replay_timestamp,
+       pg_is_wal_replay_paused() AS replay_paused,
+       current_setting('hot_standby') AS hot_standby,
+       current_setting('hot_standby_feedback') AS hot_standby_feedback,
+       current_setting('max_standby_streaming_delay') AS max_standby_streaming_delay,
+       current_setting('max_standby_archive_delay') AS max_standby_archive_delay,
+       current_setting('recovery_min_apply_delay') AS recovery_min_apply_delay,
+       current_setting('primary_slot_name') AS primary_slot_name`,
+		Role: RoleStandby,
+	},
 	{
 		Name:        "replication",
 		ArchivePath: "postgresql/replication.tsv",
 		Query:       "SELECT * FROM pg_stat_replication",
+		Volatile:    true,
+		Schema:      replicationSchema,
+	},
+	{
+		Name:        "replication_lag",
+		ArchivePath: "postgresql/replication_lag.tsv",
+		Query: `SELECT r.pid,
+       r.application_name,
+       r.client_addr,
+       r.state,
+       r.sync_state,
+       pg_wal_lsn_diff(w.lsn, r.sent_lsn) AS sent_lag_bytes,
+       pg_wal_lsn_diff(w.lsn, r.write_lsn) AS write_lag_bytes,
+       pg_wal_lsn_diff(w.lsn, r.flush_lsn) AS flush_lag_bytes,
+       pg_wal_lsn_diff(w.lsn, r.replay_lsn) AS replay_lag_bytes,
+       extract(epoch FROM r.write_lag) AS write_lag_seconds,
+       extract(epoch FROM r.flush_lag) AS flush_lag_seconds,
+       extract(epoch FROM r.replay_lag) AS replay_lag_seconds,
+       r.reply_time
+FROM pg_stat_replication r,
+     (SELECT CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END AS lsn) w
+ORDER BY r.application_name, r.pid`,
+		Volatile: true,
 	},
 	{
 		Name:        "replication_origin",
 		ArchivePath: "postgresql/replication_origin.tsv",
 		Query:       "SELECT * FROM pg_replication_origin_status",
 	},
+	{
+		// Columns added after PG12 are read through to_jsonb so the query
+		// runs on every version, NULL where missing
+		Name:        "replication_slot_lag",
+		ArchivePath: "postgresql/replication_slot_lag.tsv",
+		Query: `SELECT s.slot_name,
+       s.slot_type,
+       s.plugin,
+       s.database,
+       s.active,
+       s.active_pid,
+       to_jsonb(s) ->> 'wal_status' AS wal_status,
+       (to_jsonb(s) ->> 'safe_wal_size')::bigint AS safe_wal_size,
+       pg_wal_lsn_diff(w.lsn, s.restart_lsn) AS retained_wal_bytes,
+       pg_wal_lsn_diff(w.lsn, s.confirmed_flush_lsn) AS confirmed_flush_lag_bytes,
+       (to_jsonb(s) ->> 'inactive_since')::timestamptz AS inactive_since,
+       extract(epoch FROM now() - (to_jsonb(s) ->> 'inactive_since')::timestamptz) AS inactive_seconds,
+       age(s.xmin) AS xmin_age,
+       age(s.catalog_xmin) AS catalog_xmin_age
+FROM pg_replication_slots s,
+     (SELECT CASE WHEN pg_is_in_recovery()
+                  THEN coalesce(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())
+                  ELSE pg_current_wal_lsn() END AS lsn) w
+ORDER BY retained_wal_bytes DESC NULLS LAST, s.slot_name`,
+	},
+	{
+		Name:        "replication_slot_stats",
+		ArchivePath: "postgresql/replication_slot_stats.tsv",
+		Query:       "SELECT * FROM pg_stat_replication_slots ORDER BY slot_name",
+	},
 	{
 		Name:        "replication_slots",
 		ArchivePath: "postgresql/replication_slots.tsv",
 		Query:       "SELECT * FROM pg_replication_slots ORDER BY slot_name",
+		Schema:      replicationSlotsSchema,
+	},
+	{
+		Name:        "replication_topology",
+		ArchivePath: "postgresql/replication_topology.tsv",
+		Query: `SELECT 'node' AS kind,
+       CASE WHEN pg_is_in_recovery() THEN 'standby' ELSE 'primary' END AS name,
+       NULL AS address,
+       CASE WHEN pg_is_in_recovery() AND pg_is_wal_replay_paused() THEN 'replay paused' END AS state,
+       current_setting('cluster_name') AS detail
+UNION ALL
+SELECT 'upstream',
+       coalesce(slot_name, ''),
+       sender_host || ':' || sender_port,
+       status,
+       'timeline ' || received_tli
+FROM pg_stat_wal_receiver
+UNION ALL
+(SELECT 'downstream',
+        application_name,
+        coalesce(client_addr::text, client_hostname, 'local'),
+        state,
+        sync_state
+ FROM pg_stat_replication
+ ORDER BY application_name, pid)
+UNION ALL
+(SELECT 'slot',
+        slot_name,
+        NULL,
+        CASE WHEN active THEN 'active' ELSE 'inactive' END,
+        slot_type || coalesce(' ' || plugin, '') || coalesce(' on ' || database, '')
+ FROM pg_replication_slots
+ ORDER BY slot_name)`,
 	},
 	{
 		Name:        "roles",
 		ArchivePath: "postgresql/roles.tsv",
 		Query:       "SELECT * FROM pg_roles ORDER BY rolname",
+		Schema:      rolesSchema,
 	},
 	{
 		Name:        "running_activity_maxage",
@@ -186,81 +316,98 @@ WHERE state != 'idle'`,
 		Name:        "running_locks",
 		ArchivePath: "postgresql/running_locks.tsv",
 		Query:       "SELECT * FROM pg_locks WHERE granted ORDER BY pid, locktype",
+		Volatile:    true,
+		Schema:      runningLocksSchema,
 	},
 	{
 		Name:        "shmem_allocations",
 		ArchivePath: "postgresql/shmem_allocations.tsv",
 		Query:       "SELECT * FROM pg_shmem_allocations ORDER BY size DESC",
 	},
+	{
+		Name:        "standby_lag",
+		ArchivePath: "postgresql/standby_lag.tsv",
+		Query: `SELECT r.status,
+       r.sender_host,
+       r.sender_port,
+       r.slot_name,
+       pg_last_wal_receive_lsn() AS receive_lsn,
+       pg_last_wal_replay_lsn() AS replay_lsn,
+       pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn()) AS replay_lag_bytes,
+       pg_last_xact_replay_timestamp() AS last_xact_replay_timestamp,
+       extract(epoch FROM now() - pg_last_xact_replay_timestamp()) AS replay_delay_seconds,
+       extract(epoch FROM now() - r.last_msg_receipt_time) AS last_msg_age_seconds,
+       pg_is_wal_replay_paused() AS replay_paused
+FROM (SELECT 1) s
+LEFT JOIN pg_stat_wal_receiver r ON true`,
+		Volatile: true,
+		Role:     RoleStandby,
+	},
 	{
 		Name:        "stat_io",
 		ArchivePath: "postgresql/stat_io.tsv",
 		Query:       "SELECT * FROM pg_stat_io ORDER BY backend_type, context, object",
+		Schema:      statIOSchema,
 	},
 	{
 		Name:        "stat_progress_analyze",
 		ArchivePath: "postgresql/stat_progress_analyze.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_analyze",
+		Volatile:    true,
 	},
 	{
 		Name:        "stat_progress_basebackup",
 		ArchivePath: "postgresql/stat_progress_basebackup.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_basebackup",
+		Volatile:    true,
 	},
 	{
 		Name:        "stat_progress_cluster",
 		ArchivePath: "postgresql/stat_progress_cluster.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_cluster",
+		Volatile:    true,
 	},
 	{
 		Name:        "stat_progress_copy",
 		ArchivePath: "postgresql/stat_progress_copy.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_copy",
+		Volatile:    true,
 	},
 	{
 		Name:        "stat_progress_create_index",
 		ArchivePath: "postgresql/stat_progress_create_index.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_create_index",
+		Volatile:    true,
 	},
 	{
 		Name:        "stat_progress_vacuum",
 		ArchivePath: "postgresql/stat_progress_vacuum.tsv",
 		Query:       "SELECT * FROM pg_stat_progress_vacuum",
+		Volatile:    true,
+		Schema:      statProgressVacuumSchema,
 	},
 	{
 		Name:        "stat_slru",
 		ArchivePath: "postgresql/stat_slru.tsv",
 		Query:       "SELECT * FROM pg_stat_slru ORDER BY name",
-	},
-	{
-		Name:        "stat_statements_calls",
-		ArchivePath: "postgresql/stat_statements_calls.tsv",
-		Query:       "SELECT userid, dbid, query, calls, total_exec_time, mean_exec_time, max_exec_time, rows FROM pg_stat_statements ORDER BY calls DESC LIMIT 100",
-	},
-	{
-		Name:        "stat_statements_max_time",
-		ArchivePath: "postgresql/stat_statements_max_time.tsv",
-		Query:       "SELECT userid, dbid, query, calls, total_exec_time, mean_exec_time, max_exec_time, rows FROM pg_stat_statements ORDER BY max_exec_time DESC LIMIT 100",
-	},
-	{
-		Name:        "stat_statements_total_time",
-		ArchivePath: "postgresql/stat_statements_total_time.tsv",
-		Query:       "SELECT userid, dbid, query, calls, total_exec_time, mean_exec_time, max_exec_time, rows FROM pg_stat_statements ORDER BY total_exec_time DESC LIMIT 100",
+		Schema:      statSLRUSchema,
 	},
 	{
 		Name:        "stat_wal",
 		ArchivePath: "postgresql/stat_wal.tsv",
 		Query:       "SELECT * FROM pg_stat_wal",
+		Schema:      statWALSchema,
 	},
 	{
 		Name:        "subscriptions",
 		ArchivePath: "postgresql/subscriptions.tsv",
 		Query:       "SELECT * FROM pg_subscription ORDER BY subname",
+		Schema:      subscriptionsSchema,
 	},
 	{
 		Name:        "tablespace_sizes",
 		ArchivePath: "postgresql/tablespace_sizes.tsv",
-		Query:       "SELECT spcname, pg_size_pretty(pg_tablespace_size(oid)) AS size FROM pg_tablespace ORDER BY pg_tablespace_size(oid) DESC",
+		Query:       "SELECT spcname, pg_size_pretty(pg_tablespace_size(oid)) AS size, pg_tablespace_size(oid) AS size_bytes FROM pg_tablespace ORDER BY size_bytes DESC",
 	},
 	{
 		Name:        "tablespaces",
@@ -276,6 +423,7 @@ WHERE state != 'idle'`,
 		Name:        "waits_sample",
 		ArchivePath: "postgresql/waits_sample.tsv",
 		Query:       "SELECT pid, wait_event_type, wait_event, state, query FROM pg_stat_activity WHERE wait_event IS NOT NULL ORDER BY pid",
+		Volatile:    true,
 	},
 	{
 		Name:        "wal_position",
@@ -283,31 +431,231 @@ WHERE state != 'idle'`,
 		Query: `SELECT pg_current_wal_lsn() AS current_wal_lsn,
        pg_current_wal_insert_lsn() AS current_wal_insert_lsn,
        pg_current_wal_flush_lsn() AS current_wal_flush_lsn,
-       pg_is_in_recovery() AS is_in_recovery,
-       CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() END AS last_wal_receive_lsn,
-       CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() END AS last_wal_replay_lsn,
-       CASE WHEN pg_is_in_recovery() THEN pg_last_xact_replay_timestamp() END AS last_xact_replay_timestamp`,
+       false AS is_in_recovery,
+       NULL::pg_lsn AS last_wal_receive_lsn,
+       NULL::pg_lsn AS last_wal_replay_lsn,
+       NULL::timestamptz AS last_xact_replay_timestamp`,
+		Role: RolePrimary,
+	},
+	{
+		// pg_current_wal_*() raise an error during recovery
+		Name:        "wal_position",
+		ArchivePath: "postgresql/wal_position.tsv",
+		Query: `SELECT NULL::pg_lsn AS current_wal_lsn,
+       NULL::pg_lsn AS current_wal_insert_lsn,
+       NULL::pg_lsn AS current_wal_flush_lsn,
+       true AS is_in_recovery,
+       pg_last_wal_receive_lsn() AS last_wal_receive_lsn,
+       pg_last_wal_replay_lsn() AS last_wal_replay_lsn,
+       pg_last_xact_replay_timestamp() AS last_xact_replay_timestamp`,
+		Role: RoleStandby,
 	},
 	{
 		Name:        "wal_receiver",
 		ArchivePath: "postgresql/wal_receiver.tsv",
 		Query:       "SELECT * FROM pg_stat_wal_receiver",
+		Schema:      walReceiverSchema,
+	},
+	{
+		Name:        "wraparound",
+		ArchivePath: "postgresql/wraparound.tsv",
+		Query: `SELECT datname,
+       datfrozenxid,
+       age(datfrozenxid) AS xid_age,
+       round(100.0 * age(datfrozenxid) / current_setting('autovacuum_freeze_max_age')::bigint, 2) AS xid_pct_freeze_max_age,
+       round(100.0 * age(datfrozenxid) / 2147483648, 2) AS xid_pct_wraparound,
+       datminmxid,
+       mxid_age(datminmxid) AS mxid_age,
+       round(100.0 * mxid_age(datminmxid) / current_setting('autovacuum_multixact_freeze_max_age')::bigint, 2) AS mxid_pct_freeze_max_age,
+       round(100.0 * mxid_age(datminmxid) / 2147483648, 2) AS mxid_pct_wraparound
+FROM pg_database
+ORDER BY age(datfrozenxid) DESC`,
 	},
 }
 
 // Per-database query tasks (sorted alphabetically by name)
 // These are per-database tasks - ArchivePath will be formatted with dbname
 var perDatabaseQueryTasks = []SimpleQueryTask{
+	{
+		Name:        "duplicate_indexes",
+		ArchivePath: "databases/%s/duplicate_indexes.tsv",
+		// An index is reported once against the index that makes it
+		// redundant: exact duplicates keep the primary key or unique index
+		// (else the oldest), and a non-unique btree index is a prefix
+		// duplicate when its columns lead another btree index
+		Query: `WITH idx AS (
+    SELECT i.indexrelid,
+           i.indrelid,
+           i.indisprimary::int * 2 + i.indisunique::int AS rank,
+           i.indisunique,
+           i.indkey::text AS keys,
+           i.indclass::text AS classes,
+           i.indcollation::text AS collations,
+           coalesce(pg_get_expr(i.indexprs, i.indrelid), '') AS exprs,
+           coalesce(pg_get_expr(i.indpred, i.indrelid), '') AS pred,
+           c.relam
+    FROM pg_index i
+    JOIN pg_class c ON c.oid = i.indexrelid
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+      AND n.nspname !~ '^pg_toast'
+)
+SELECT n.nspname AS schemaname,
+       t.relname AS tablename,
+       a.indexrelid::regclass AS indexname,
+       b.indexrelid::regclass AS duplicate_of,
+       CASE WHEN a.keys = b.keys THEN 'exact' ELSE 'prefix' END AS kind,
+       pg_relation_size(a.indexrelid) AS size_bytes,
+       pg_get_indexdef(a.indexrelid) AS indexdef,
+       pg_get_indexdef(b.indexrelid) AS duplicate_of_def
+FROM idx a
+JOIN idx b ON b.indrelid = a.indrelid
+          AND b.indexrelid <> a.indexrelid
+          AND b.relam = a.relam
+          AND b.exprs = a.exprs
+          AND b.pred = a.pred
+JOIN pg_class t ON t.oid = a.indrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+WHERE (a.keys = b.keys
+       AND a.classes = b.classes
+       AND a.collations = b.collations
+       AND (a.rank < b.rank OR (a.rank = b.rank AND a.indexrelid > b.indexrelid)))
+   OR (NOT a.indisunique
+       AND a.exprs = ''
+       AND a.relam = (SELECT oid FROM pg_am WHERE amname = 'btree')
+       AND b.keys LIKE a.keys || ' %'
+       AND b.classes LIKE a.classes || ' %'
+       AND b.collations LIKE a.collations || ' %')
+ORDER BY size_bytes DESC, schemaname, tablename, indexname
+LIMIT :top_n`,
+	},
 	{
 		Name:        "extensions",
 		ArchivePath: "databases/%s/extensions.tsv",
 		Query:       "SELECT * FROM pg_extension ORDER BY extname",
+		Schema:      extensionsSchema,
+	},
+	{
+		Name:        "freeze_age",
+		ArchivePath: "databases/%s/freeze_age.tsv",
+		Query: `WITH rels AS (
+    SELECT c.oid, age(c.relfrozenxid) AS xid_age, mxid_age(c.relminmxid) AS mxid_age
+    FROM pg_class c
+    WHERE c.relkind IN ('r', 'm', 't')
+), top AS (
+    (SELECT oid FROM rels ORDER BY xid_age DESC LIMIT :top_n)
+    UNION
+    (SELECT oid FROM rels ORDER BY mxid_age DESC LIMIT :top_n)
+)
+SELECT n.nspname AS schemaname,
+       c.relname,
+       c.relkind,
+       owner.oid::regclass AS toast_of,
+       c.relfrozenxid,
+       rels.xid_age,
+       round(100.0 * rels.xid_age / current_setting('autovacuum_freeze_max_age')::bigint, 2) AS xid_pct_freeze_max_age,
+       c.relminmxid,
+       rels.mxid_age,
+       round(100.0 * rels.mxid_age / current_setting('autovacuum_multixact_freeze_max_age')::bigint, 2) AS mxid_pct_freeze_max_age,
+       pg_relation_size(c.oid) AS size_bytes,
+       s.last_vacuum,
+       s.last_autovacuum
+FROM top
+JOIN rels ON rels.oid = top.oid
+JOIN pg_class c ON c.oid = top.oid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_class owner ON owner.reltoastrelid = c.oid
+LEFT JOIN pg_stat_all_tables s ON s.relid = c.oid
+ORDER BY rels.xid_age DESC, rels.mxid_age DESC`,
 	},
 	{
 		Name:        "funcs",
 		ArchivePath: "databases/%s/funcs.tsv",
 		Query:       "SELECT oid, proname, pronamespace, proowner, prolang, prokind FROM pg_proc WHERE prokind = 'f' ORDER BY proname",
 	},
+	{
+		Name:        "index_bloat",
+		ArchivePath: "databases/%s/index_bloat.tsv",
+		// Estimates btree index bloat from pg_class and pg_stats without
+		// reading the indexes (after ioguix's btree_bloat.sql)
+		Query: `WITH idx AS (
+    SELECT ci.relname AS indexname,
+           ci.reltuples,
+           ci.relpages,
+           i.indrelid AS tbloid,
+           i.indexrelid AS idxoid,
+           coalesce(substring(array_to_string(ci.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 90) AS fillfactor,
+           string_to_array(textin(int2vectorout(i.indkey)), ' ')::int[] AS indkey,
+           generate_series(1, i.indnatts) AS attpos
+    FROM pg_index i
+    JOIN pg_class ci ON ci.oid = i.indexrelid
+    WHERE ci.relam = (SELECT oid FROM pg_am WHERE amname = 'btree')
+      AND ci.relpages > 0
+), cols AS (
+    SELECT n.nspname AS schemaname,
+           ct.relname AS tablename,
+           idx.indexname,
+           idx.reltuples,
+           idx.relpages,
+           idx.fillfactor,
+           coalesce(a1.attname, a2.attname) AS attname,
+           coalesce(a1.atttypid, a2.atttypid) AS atttypid,
+           CASE WHEN a1.attnum IS NULL THEN idx.indexname ELSE ct.relname END AS attrelname
+    FROM idx
+    JOIN pg_class ct ON ct.oid = idx.tbloid
+    JOIN pg_namespace n ON n.oid = ct.relnamespace
+    LEFT JOIN pg_attribute a1 ON idx.indkey[idx.attpos] <> 0
+                             AND a1.attrelid = idx.tbloid
+                             AND a1.attnum = idx.indkey[idx.attpos]
+    LEFT JOIN pg_attribute a2 ON idx.indkey[idx.attpos] = 0
+                             AND a2.attrelid = idx.idxoid
+                             AND a2.attnum = idx.attpos
+    WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+), stats AS (
+    SELECT cols.schemaname,
+           cols.tablename,
+           cols.indexname,
+           cols.reltuples,
+           cols.relpages,
+           cols.fillfactor,
+           current_setting('block_size')::numeric AS bs,
+           CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS maxalign,
+           24 AS pagehdr,
+           16 AS pageopqdata,
+           CASE WHEN max(coalesce(s.null_frac, 0)) = 0 THEN 8 ELSE 8 + ((32 + 8 - 1) / 8) END AS index_tuple_hdr_bm,
+           sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 1024)) AS nulldatawidth,
+           bool_or(cols.atttypid = 'pg_catalog.name'::regtype) AS estimate_unreliable
+    FROM cols
+    JOIN pg_stats s ON s.schemaname = cols.schemaname
+                   AND s.tablename = cols.attrelname
+                   AND s.attname = cols.attname
+    GROUP BY 1, 2, 3, 4, 5, 6
+), widths AS (
+    SELECT *,
+           (index_tuple_hdr_bm + maxalign
+            - CASE WHEN index_tuple_hdr_bm % maxalign = 0 THEN maxalign ELSE index_tuple_hdr_bm % maxalign END
+            + nulldatawidth + maxalign
+            - CASE WHEN nulldatawidth = 0 THEN 0
+                   WHEN nulldatawidth::integer % maxalign = 0 THEN maxalign
+                   ELSE nulldatawidth::integer % maxalign END)::numeric AS nulldatahdrwidth
+    FROM stats
+), estimates AS (
+    SELECT *,
+           coalesce(1 + ceil(reltuples / floor((bs - pageopqdata - pagehdr) * fillfactor / (100 * (4 + nulldatahdrwidth)::float))), 0) AS est_pages
+    FROM widths
+)
+SELECT schemaname,
+       tablename,
+       indexname,
+       (bs * relpages)::bigint AS size_bytes,
+       fillfactor,
+       (bs * greatest(relpages - est_pages, 0))::bigint AS wasted_bytes,
+       round((100 * greatest(relpages - est_pages, 0) / relpages)::numeric, 1) AS bloat_pct,
+       estimate_unreliable
+FROM estimates
+ORDER BY wasted_bytes DESC, schemaname, tablename, indexname
+LIMIT :top_n`,
+	},
 	{
 		Name:        "indexes",
 		ArchivePath: "databases/%s/indexes.tsv",
@@ -317,16 +665,92 @@ var perDatabaseQueryTasks = []SimpleQueryTask{
 			ORDER BY schemaname, tablename, indexname
 		`,
 	},
+	{
+		Name:        "invalid_indexes",
+		ArchivePath: "databases/%s/invalid_indexes.tsv",
+		Query: `SELECT n.nspname AS schemaname,
+       t.relname AS tablename,
+       c.relname AS indexname,
+       i.indisvalid,
+       i.indisready,
+       i.indislive,
+       pg_relation_size(i.indexrelid) AS size_bytes,
+       pg_get_indexdef(i.indexrelid) AS indexdef
+FROM pg_index i
+JOIN pg_class c ON c.oid = i.indexrelid
+JOIN pg_class t ON t.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE NOT i.indisvalid OR NOT i.indisready
+ORDER BY n.nspname, t.relname, c.relname`,
+	},
 	{
 		Name:        "languages",
 		ArchivePath: "databases/%s/languages.tsv",
 		Query:       "SELECT * FROM pg_language ORDER BY lanname",
 	},
+	{
+		Name:        "missing_fk_indexes",
+		ArchivePath: "databases/%s/missing_fk_indexes.tsv",
+		// A foreign key is supported by a non-partial index whose leading
+		// columns are the referencing columns, in any order
+		Query: `SELECT n.nspname AS schemaname,
+       t.relname AS tablename,
+       c.conname,
+       array_to_string(ARRAY(
+           SELECT a.attname
+           FROM unnest(c.conkey) WITH ORDINALITY k(attnum, ord)
+           JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+           ORDER BY k.ord), ', ') AS columns,
+       c.confrelid::regclass AS referenced_table,
+       pg_relation_size(c.conrelid) AS table_size_bytes,
+       pg_relation_size(c.confrelid) AS referenced_size_bytes
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+WHERE c.contype = 'f'
+  AND c.conparentid = 0
+  AND NOT EXISTS (
+      SELECT 1
+      FROM pg_index i
+      WHERE i.indrelid = c.conrelid
+        AND i.indpred IS NULL
+        AND (i.indkey::int2[])[0:cardinality(c.conkey) - 1] @> c.conkey
+  )
+ORDER BY table_size_bytes DESC, schemaname, tablename, c.conname
+LIMIT :top_n`,
+	},
 	{
 		Name:        "operators",
 		ArchivePath: "databases/%s/operators.tsv",
 		Query:       "SELECT oid, oprname, oprkind, oprcanmerge, oprcanhash FROM pg_operator ORDER BY oprname",
 	},
+	{
+		Name:        "partition_tree_sizes",
+		ArchivePath: "databases/%s/partition_tree_sizes.tsv",
+		Query: `WITH tree AS (
+    SELECT c.oid AS root, t.relid, t.isleaf, t.level
+    FROM pg_class c
+    CROSS JOIN LATERAL pg_partition_tree(c.oid) t
+    WHERE c.relkind = 'p'
+      AND NOT c.relispartition
+)
+SELECT n.nspname AS schemaname,
+       c.relname,
+       count(*) FILTER (WHERE tree.isleaf) AS leaf_partitions,
+       max(tree.level) AS depth,
+       (sum(rel.reltuples) FILTER (WHERE rel.reltuples > 0))::bigint AS reltuples,
+       sum(pg_relation_size(tree.relid)) AS heap_bytes,
+       sum(coalesce(pg_total_relation_size(rel.reltoastrelid), 0)) AS toast_bytes,
+       sum(pg_indexes_size(tree.relid)) AS index_bytes,
+       sum(pg_total_relation_size(tree.relid)) AS total_bytes
+FROM tree
+JOIN pg_class c ON c.oid = tree.root
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_class rel ON rel.oid = tree.relid
+GROUP BY n.nspname, c.relname
+ORDER BY total_bytes DESC
+LIMIT :top_n`,
+	},
 	{
 		Name:        "partitioned_tables",
 		ArchivePath: "databases/%s/partitioned_tables.tsv",
@@ -358,6 +782,32 @@ var perDatabaseQueryTasks = []SimpleQueryTask{
 		ArchivePath: "databases/%s/publications.tsv",
 		Query:       "SELECT * FROM pg_publication ORDER BY pubname",
 	},
+	{
+		Name:        "relation_sizes",
+		ArchivePath: "databases/%s/relation_sizes.tsv",
+		Query: `SELECT n.nspname AS schemaname,
+       c.relname,
+       c.relkind,
+       c.relpersistence,
+       coalesce(ts.spcname, dts.spcname) AS tablespace,
+       inh.inhparent::regclass AS partition_of,
+       CASE WHEN c.relispartition THEN pg_partition_root(c.oid) END AS partition_root,
+       c.reltuples::bigint AS reltuples,
+       c.relpages,
+       pg_relation_size(c.oid) AS heap_bytes,
+       coalesce(pg_total_relation_size(c.reltoastrelid), 0) AS toast_bytes,
+       pg_indexes_size(c.oid) AS index_bytes,
+       pg_total_relation_size(c.oid) AS total_bytes
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_database d ON d.datname = current_database()
+JOIN pg_tablespace dts ON dts.oid = d.dattablespace
+LEFT JOIN pg_tablespace ts ON ts.oid = c.reltablespace
+LEFT JOIN pg_inherits inh ON inh.inhrelid = c.oid AND c.relispartition
+WHERE c.relkind IN ('r', 'm')
+ORDER BY total_bytes DESC
+LIMIT :top_n`,
+	},
 	{
 		Name:        "schemas",
 		ArchivePath: "databases/%s/schemas.tsv",
@@ -374,6 +824,83 @@ var perDatabaseQueryTasks = []SimpleQueryTask{
        stats_reset
 FROM pg_stat_database
 WHERE datname = current_database()`,
+	},
+	{
+		Name:        "stat_user_functions",
+		ArchivePath: "databases/%s/stat_user_functions.tsv",
+		Query: `SELECT funcid, schemaname, funcname, calls, total_time, self_time
+FROM pg_stat_user_functions
+ORDER BY total_time DESC
+LIMIT :top_n`,
+	},
+	{
+		Name:        "stat_user_indexes",
+		ArchivePath: "databases/%s/stat_user_indexes.tsv",
+		Query: `WITH top AS (
+    (SELECT indexrelid FROM pg_stat_user_indexes ORDER BY idx_scan DESC NULLS LAST LIMIT :top_n)
+    UNION
+    (SELECT indexrelid FROM pg_stat_user_indexes ORDER BY idx_tup_read DESC NULLS LAST LIMIT :top_n)
+)
+SELECT s.relid, s.indexrelid, s.schemaname, s.relname, s.indexrelname,
+       s.idx_scan, s.idx_tup_read, s.idx_tup_fetch
+FROM top
+JOIN pg_stat_user_indexes s USING (indexrelid)
+ORDER BY s.schemaname, s.relname, s.indexrelname`,
+	},
+	{
+		Name:        "stat_user_tables",
+		ArchivePath: "databases/%s/stat_user_tables.tsv",
+		Query: `WITH top AS (
+    (SELECT relid FROM pg_stat_user_tables ORDER BY seq_tup_read DESC NULLS LAST LIMIT :top_n)
+    UNION
+    (SELECT relid FROM pg_stat_user_tables ORDER BY idx_tup_fetch DESC NULLS LAST LIMIT :top_n)
+    UNION
+    (SELECT relid FROM pg_stat_user_tables ORDER BY n_tup_ins + n_tup_upd + n_tup_del DESC LIMIT :top_n)
+    UNION
+    (SELECT relid FROM pg_stat_user_tables ORDER BY n_dead_tup DESC LIMIT :top_n)
+)
+SELECT s.relid, s.schemaname, s.relname,
+       s.seq_scan, s.seq_tup_read, s.idx_scan, s.idx_tup_fetch,
+       s.n_tup_ins, s.n_tup_upd, s.n_tup_del, s.n_tup_hot_upd,
+       s.n_live_tup, s.n_dead_tup, s.n_mod_since_analyze,
+       s.last_vacuum, s.last_autovacuum, s.last_analyze, s.last_autoanalyze,
+       s.vacuum_count, s.autovacuum_count, s.analyze_count, s.autoanalyze_count
+FROM top
+JOIN pg_stat_user_tables s USING (relid)
+ORDER BY s.schemaname, s.relname`,
+	},
+	{
+		Name:        "statio_user_indexes",
+		ArchivePath: "databases/%s/statio_user_indexes.tsv",
+		Query: `WITH top AS (
+    (SELECT indexrelid FROM pg_statio_user_indexes ORDER BY idx_blks_read DESC NULLS LAST LIMIT :top_n)
+    UNION
+    (SELECT indexrelid FROM pg_statio_user_indexes ORDER BY idx_blks_hit DESC NULLS LAST LIMIT :top_n)
+)
+SELECT s.relid, s.indexrelid, s.schemaname, s.relname, s.indexrelname,
+       s.idx_blks_read, s.idx_blks_hit
+FROM top
+JOIN pg_statio_user_indexes s USING (indexrelid)
+ORDER BY s.schemaname, s.relname, s.indexrelname`,
+	},
+	{
+		Name:        "statio_user_tables",
+		ArchivePath: "databases/%s/statio_user_tables.tsv",
+		Query: `WITH top AS (
+    (SELECT relid FROM pg_statio_user_tables
+     ORDER BY heap_blks_read + coalesce(idx_blks_read, 0) + coalesce(toast_blks_read, 0) + coalesce(tidx_blks_read, 0) DESC
+     LIMIT :top_n)
+    UNION
+    (SELECT relid FROM pg_statio_user_tables
+     ORDER BY heap_blks_hit + coalesce(idx_blks_hit, 0) + coalesce(toast_blks_hit, 0) + coalesce(tidx_blks_hit, 0) DESC
+     LIMIT :top_n)
+)
+SELECT s.relid, s.schemaname, s.relname,
+       s.heap_blks_read, s.heap_blks_hit, s.idx_blks_read, s.idx_blks_hit,
+       s.toast_blks_read, s.toast_blks_hit, s.tidx_blks_read, s.tidx_blks_hit
+FROM top
+JOIN pg_statio_user_tables s USING (relid)
+ORDER BY s.schemaname, s.relname`,
 	},
 	{
 		Name:        "statistics",
@@ -385,6 +912,62 @@ WHERE datname = current_database()`,
 		ArchivePath: "databases/%s/subscription_tables.tsv",
 		Query:       "SELECT * FROM pg_subscription_rel ORDER BY srsubid, srrelid",
 	},
+	{
+		Name:        "table_bloat",
+		ArchivePath: "databases/%s/table_bloat.tsv",
+		// Estimates table bloat from pg_class and pg_stats without reading
+		// the tables (after ioguix's table_bloat.sql)
+		Query: `WITH cols AS (
+    SELECT tbl.oid,
+           ns.nspname AS schemaname,
+           tbl.relname AS tablename,
+           tbl.reltuples,
+           tbl.relpages AS heap_pages,
+           coalesce(toast.relpages, 0) AS toast_pages,
+           coalesce(toast.reltuples, 0) AS toast_tuples,
+           coalesce(substring(array_to_string(tbl.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 100) AS fillfactor,
+           current_setting('block_size')::numeric AS bs,
+           CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS ma,
+           24 AS page_hdr,
+           23 + CASE WHEN max(coalesce(s.null_frac, 0)) > 0 THEN (7 + count(s.attname)) / 8 ELSE 0 END AS tpl_hdr_size,
+           sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 0)) AS tpl_data_size,
+           bool_or(att.atttypid = 'pg_catalog.name'::regtype) OR count(*) <> count(s.attname) AS estimate_unreliable
+    FROM pg_attribute att
+    JOIN pg_class tbl ON tbl.oid = att.attrelid
+    JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+    LEFT JOIN pg_stats s ON s.schemaname = ns.nspname
+                        AND s.tablename = tbl.relname
+                        AND NOT s.inherited
+                        AND s.attname = att.attname
+    LEFT JOIN pg_class toast ON toast.oid = tbl.reltoastrelid
+    WHERE att.attnum > 0
+      AND NOT att.attisdropped
+      AND tbl.relkind IN ('r', 'm')
+      AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+    GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11
+), sizes AS (
+    SELECT *,
+           heap_pages + toast_pages AS pages,
+           4 + tpl_hdr_size + tpl_data_size + 2 * ma
+           - CASE WHEN tpl_hdr_size % ma = 0 THEN ma ELSE tpl_hdr_size % ma END
+           - CASE WHEN ceil(tpl_data_size)::int % ma = 0 THEN ma ELSE ceil(tpl_data_size)::int % ma END AS tpl_size
+    FROM cols
+), estimates AS (
+    SELECT *,
+           ceil(reltuples / ((bs - page_hdr) * fillfactor / (tpl_size * 100))) + ceil(toast_tuples / 4) AS est_pages
+    FROM sizes
+)
+SELECT schemaname,
+       tablename,
+       (bs * pages)::bigint AS size_bytes,
+       fillfactor,
+       (bs * greatest(pages - est_pages, 0))::bigint AS wasted_bytes,
+       CASE WHEN pages > 0 THEN round((100 * greatest(pages - est_pages, 0) / pages)::numeric, 1) ELSE 0 END AS bloat_pct,
+       estimate_unreliable
+FROM estimates
+ORDER BY wasted_bytes DESC, schemaname, tablename
+LIMIT :top_n`,
+	},
 	{
 		Name:        "tables",
 		ArchivePath: "databases/%s/tables.tsv",
@@ -398,12 +981,70 @@ WHERE datname = current_database()`,
 		Name:        "triggers",
 		ArchivePath: "databases/%s/triggers.tsv",
 		Query:       "SELECT * FROM pg_trigger ORDER BY tgname",
+		Schema:      triggersSchema,
 	},
 	{
 		Name:        "types",
 		ArchivePath: "databases/%s/types.tsv",
 		Query:       "SELECT oid, typname, typnamespace, typtype, typcategory FROM pg_type ORDER BY typname",
 	},
+	{
+		Name:        "unused_indexes",
+		ArchivePath: "databases/%s/unused_indexes.tsv",
+		// Unique, primary key and exclusion constraint indexes enforce
+		// constraints even when never scanned
+		Query: `SELECT s.schemaname,
+       s.relname AS tablename,
+       s.indexrelname AS indexname,
+       s.idx_scan,
+       pg_relation_size(s.indexrelid) AS size_bytes,
+       pg_get_indexdef(s.indexrelid) AS indexdef,
+       d.stats_reset
+FROM pg_stat_user_indexes s
+JOIN pg_index i ON i.indexrelid = s.indexrelid
+LEFT JOIN pg_stat_database d ON d.datname = current_database()
+WHERE s.idx_scan = 0
+  AND NOT i.indisunique
+  AND NOT i.indisprimary
+  AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid)
+ORDER BY size_bytes DESC, s.schemaname, s.relname, s.indexrelname
+LIMIT :top_n`,
+	},
+}
+
+// Per-database bloat measurement with pgstattuple_approx (--exact-bloat).
+// Unlike the estimates, it reads the pages that the visibility map does not
+// mark all-visible, so it is limited to the largest tables.
+// Unlogged tables cannot be read during recovery and are left out on a
+// standby.
+var exactBloatQueryTasks = []SimpleQueryTask{
+	{
+		Name:        "table_bloat_exact",
+		ArchivePath: "databases/%s/table_bloat_exact.tsv",
+		Query: `SELECT c.schemaname,
+       c.tablename,
+       s.table_len AS size_bytes,
+       s.scanned_percent,
+       s.approx_tuple_count,
+       s.approx_tuple_len,
+       s.dead_tuple_count,
+       s.dead_tuple_len,
+       s.approx_free_space,
+       s.dead_tuple_len + s.approx_free_space AS wasted_bytes,
+       round((s.dead_tuple_percent + s.approx_free_percent)::numeric, 1) AS bloat_pct
+FROM (
+    SELECT c.oid, n.nspname AS schemaname, c.relname AS tablename
+    FROM pg_class c
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE c.relkind IN ('r', 'm')
+      AND (c.relpersistence = 'p' OR (c.relpersistence = 'u' AND NOT pg_is_in_recovery()))
+      AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+    ORDER BY pg_relation_size(c.oid) DESC
+    LIMIT :top_n
+) c
+CROSS JOIN LATERAL pgstattuple_approx(c.oid) s
+ORDER BY wasted_bytes DESC, c.schemaname, c.tablename`,
+	},
 }
 
 // pg_statviz extension query tasks (sorted alphabetically by name)
@@ -413,16 +1054,19 @@ var pgStatvizQueryTasks = []SimpleQueryTask{
 		Name:        "pg_statviz_buf",
 		ArchivePath: "pg_statviz/%s/buf.tsv",
 		Query:       "SELECT * FROM pgstatviz.buf ORDER BY snapshot_tstamp",
+		Schema:      statvizBufSchema,
 	},
 	{
 		Name:        "pg_statviz_conf",
 		ArchivePath: "pg_statviz/%s/conf.tsv",
 		Query:       "SELECT * FROM pgstatviz.conf ORDER BY snapshot_tstamp",
+		Schema:      statvizConfSchema,
 	},
 	{
 		Name:        "pg_statviz_conn",
 		ArchivePath: "pg_statviz/%s/conn.tsv",
 		Query:       "SELECT * FROM pgstatviz.conn ORDER BY snapshot_tstamp",
+		Schema:      statvizConnSchema,
 	},
 	{
 		Name:        "pg_statviz_db",
@@ -433,53 +1077,85 @@ var pgStatvizQueryTasks = []SimpleQueryTask{
 		Name:        "pg_statviz_io",
 		ArchivePath: "pg_statviz/%s/io.tsv",
 		Query:       "SELECT * FROM pgstatviz.io ORDER BY snapshot_tstamp",
+		Schema:      statvizIOSchema,
 	},
 	{
 		Name:        "pg_statviz_lock",
 		ArchivePath: "pg_statviz/%s/lock.tsv",
 		Query:       "SELECT * FROM pgstatviz.lock ORDER BY snapshot_tstamp",
+		Schema:      statvizLockSchema,
 	},
 	{
 		Name:        "pg_statviz_repl",
 		ArchivePath: "pg_statviz/%s/repl.tsv",
 		Query:       "SELECT * FROM pgstatviz.repl ORDER BY snapshot_tstamp",
+		Schema:      statvizReplSchema,
 	},
 	{
 		Name:        "pg_statviz_slru",
 		ArchivePath: "pg_statviz/%s/slru.tsv",
 		Query:       "SELECT * FROM pgstatviz.slru ORDER BY snapshot_tstamp",
+		Schema:      statvizSLRUSchema,
 	},
 	{
 		Name:        "pg_statviz_snapshots",
 		ArchivePath: "pg_statviz/%s/snapshots.tsv",
 		Query:       "SELECT * FROM pgstatviz.snapshots ORDER BY snapshot_tstamp",
+		Schema:      statvizSnapshotsSchema,
 	},
 	{
 		Name:        "pg_statviz_wait",
 		ArchivePath: "pg_statviz/%s/wait.tsv",
 		Query:       "SELECT * FROM pgstatviz.wait ORDER BY snapshot_tstamp",
+		Schema:      statvizWaitSchema,
 	},
 	{
 		Name:        "pg_statviz_wal",
 		ArchivePath: "pg_statviz/%s/wal.tsv",
 		Query:       "SELECT * FROM pgstatviz.wal ORDER BY snapshot_tstamp",
+		Schema:      statvizWALSchema,
 	},
 }
 
 // buildQueryTasks converts SimpleQueryTask registry to CollectionTask slice
 func buildQueryTasks(category string, tasks []SimpleQueryTask, db *sql.DB) []CollectionTask {
-	result := make([]CollectionTask, len(tasks))
-	for i, t := range tasks {
-		result[i] = CollectionTask{
-			Category:    category,
-			Name:        t.Name,
-			ArchivePath: t.ArchivePath,
-			Collector:   pgQueryCollector(db, t.Query),
+	var result []CollectionTask
+	for _, t := range tasks {
+		for _, task := range queryTasks(category, t.Name, t.ArchivePath, t.Schema, func(schema *OutputSchema) func(*Config, io.Writer) error {
+			return pgQueryCollector(db, t.Query, schema)
+		}) {
+			task.Role = t.Role
+			result = append(result, task)
 		}
 	}
 	return result
 }
 
+// queryTasks returns the collection task of a query and, if it has a stable
+// schema, the task writing its unmodified output for --raw-select. collector
+// returns the query's collector for a schema, nil meaning unmodified.
+func queryTasks(category, name, archivePath string, schema *OutputSchema, collector func(*OutputSchema) func(*Config, io.Writer) error) []CollectionTask {
+	task := CollectionTask{
+		Category:    category,
+		Name:        name,
+		ArchivePath: archivePath,
+		Collector:   collector(schema),
+		QueryResult: true,
+		Schema:      schema,
+	}
+	if schema == nil {
+		return []CollectionTask{task}
+	}
+	return []CollectionTask{task, {
+		Category:    category,
+		Name:        name + RawSelectSuffix,
+		ArchivePath: rawSelectPath(archivePath),
+		Collector:   collector(nil),
+		QueryResult: true,
+		Raw:         true,
+	}}
+}
+
 // buildConfigFileTasks converts SimpleConfigFileTask registry to CollectionTask slice
 func buildConfigFileTasks(category string, tasks []SimpleConfigFileTask, db *sql.DB) []CollectionTask {
 	result := make([]CollectionTask, len(tasks))
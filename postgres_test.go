@@ -13,6 +13,8 @@ package main
 import (
 	"bytes"
 	"errors"
+	"regexp"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -32,6 +34,7 @@ func TestPostgreSQLCollectors(t *testing.T) {
 		{"activity", "postgresql/running_activity.tsv"},
 		{"archiver", "postgresql/archiver.tsv"},
 		{"available_extensions", "postgresql/available_extensions.tsv"},
+		{"backend_os_stats", "postgresql/backend_os_stats.tsv"},
 		{"bgwriter", "postgresql/bgwriter.tsv"},
 		{"blocking_locks", "postgresql/blocking_locks.tsv"},
 		{"checkpointer", "postgresql/checkpointer.tsv"},
@@ -55,13 +58,20 @@ func TestPostgreSQLCollectors(t *testing.T) {
 		{"prepared_xacts", "postgresql/prepared_xacts.tsv"},
 		{"recovery.conf", "postgresql/recovery.conf"},
 		{"recovery.done", "postgresql/recovery.done"},
+		{"recovery_conflicts", "postgresql/recovery_conflicts.tsv"},
+		{"recovery_status", "postgresql/recovery_status.tsv"},
 		{"replication", "postgresql/replication.tsv"},
+		{"replication_lag", "postgresql/replication_lag.tsv"},
 		{"replication_origin", "postgresql/replication_origin.tsv"},
+		{"replication_slot_lag", "postgresql/replication_slot_lag.tsv"},
+		{"replication_slot_stats", "postgresql/replication_slot_stats.tsv"},
 		{"replication_slots", "postgresql/replication_slots.tsv"},
+		{"replication_topology", "postgresql/replication_topology.tsv"},
 		{"roles", "postgresql/roles.tsv"},
 		{"running_activity_maxage", "postgresql/running_activity_maxage.tsv"},
 		{"running_locks", "postgresql/running_locks.tsv"},
 		{"shmem_allocations", "postgresql/shmem_allocations.tsv"},
+		{"standby_lag", "postgresql/standby_lag.tsv"},
 		{"stat_io", "postgresql/stat_io.tsv"},
 		{"stat_progress_analyze", "postgresql/stat_progress_analyze.tsv"},
 		{"stat_progress_basebackup", "postgresql/stat_progress_basebackup.tsv"},
@@ -70,9 +80,17 @@ func TestPostgreSQLCollectors(t *testing.T) {
 		{"stat_progress_create_index", "postgresql/stat_progress_create_index.tsv"},
 		{"stat_progress_vacuum", "postgresql/stat_progress_vacuum.tsv"},
 		{"stat_slru", "postgresql/stat_slru.tsv"},
+		{"stat_statements_blks_read", "postgresql/stat_statements_blks_read.tsv"},
 		{"stat_statements_calls", "postgresql/stat_statements_calls.tsv"},
+		{"stat_statements_info", "postgresql/stat_statements_info.tsv"},
+		{"stat_statements_io_time", "postgresql/stat_statements_io_time.tsv"},
 		{"stat_statements_max_time", "postgresql/stat_statements_max_time.tsv"},
+		{"stat_statements_mean_time", "postgresql/stat_statements_mean_time.tsv"},
+		{"stat_statements_plan_time", "postgresql/stat_statements_plan_time.tsv"},
+		{"stat_statements_rows", "postgresql/stat_statements_rows.tsv"},
+		{"stat_statements_temp_blks", "postgresql/stat_statements_temp_blks.tsv"},
 		{"stat_statements_total_time", "postgresql/stat_statements_total_time.tsv"},
+		{"stat_statements_wal_bytes", "postgresql/stat_statements_wal_bytes.tsv"},
 		{"stat_wal", "postgresql/stat_wal.tsv"},
 		{"subscriptions", "postgresql/subscriptions.tsv"},
 		{"tablespace_sizes", "postgresql/tablespace_sizes.tsv"},
@@ -81,46 +99,77 @@ func TestPostgreSQLCollectors(t *testing.T) {
 		{"waits_sample", "postgresql/waits_sample.tsv"},
 		{"wal_position", "postgresql/wal_position.tsv"},
 		{"wal_receiver", "postgresql/wal_receiver.tsv"},
+		{"wraparound", "postgresql/wraparound.tsv"},
 	}
 
-	tasks := getPostgreSQLTasks(nil)
+	// backend_os_stats reads /proc and is only registered on Linux
+	if runtime.GOOS != "linux" {
+		filtered := expected[:0]
+		for _, exp := range expected {
+			if exp.name != "backend_os_stats" {
+				filtered = append(filtered, exp)
+			}
+		}
+		expected = filtered
+	}
 
-	if len(tasks) == 0 {
+	// Collectors that only run on a standby; wal_position has a variant
+	// for each role
+	standbyOnly := map[string]bool{"recovery_conflicts": true, "recovery_status": true, "standby_lag": true}
+
+	// The unmodified SELECT * variants only run with --raw-select
+	all := withoutRawTasks(getPostgreSQLTasks(nil))
+
+	if len(all) == 0 {
 		t.Fatal("getPostgreSQLTasks returned no tasks")
 	}
 
-	// Build a map for easy lookup
-	taskMap := make(map[string]*CollectionTask)
-	for i := range tasks {
-		taskMap[tasks[i].Name] = &tasks[i]
-	}
+	for _, role := range []string{RolePrimary, RoleStandby} {
+		t.Run(role, func(t *testing.T) {
+			tasks := forServerRole(all, role)
 
-	// Verify each expected collector exists with correct metadata
-	for _, exp := range expected {
-		t.Run(exp.name, func(t *testing.T) {
-			task, found := taskMap[exp.name]
-			if !found {
-				t.Fatalf("collector %q not found in getPostgreSQLTasks()", exp.name)
+			// Build a map for easy lookup
+			taskMap := make(map[string]*CollectionTask)
+			for i := range tasks {
+				taskMap[tasks[i].Name] = &tasks[i]
 			}
 
-			if task.Category != "postgresql" {
-				t.Errorf("expected category 'postgresql', got %q", task.Category)
-			}
+			// Verify each expected collector exists with correct metadata
+			count := 0
+			for _, exp := range expected {
+				if role == RolePrimary && standbyOnly[exp.name] {
+					if _, found := taskMap[exp.name]; found {
+						t.Errorf("standby collector %q runs on a primary", exp.name)
+					}
+					continue
+				}
+				count++
+				t.Run(exp.name, func(t *testing.T) {
+					task, found := taskMap[exp.name]
+					if !found {
+						t.Fatalf("collector %q not found in getPostgreSQLTasks()", exp.name)
+					}
 
-			if task.ArchivePath != exp.archivePath {
-				t.Errorf("expected archive path %q, got %q", exp.archivePath, task.ArchivePath)
+					if task.Category != "postgresql" {
+						t.Errorf("expected category 'postgresql', got %q", task.Category)
+					}
+
+					if task.ArchivePath != exp.archivePath {
+						t.Errorf("expected archive path %q, got %q", exp.archivePath, task.ArchivePath)
+					}
+
+					if task.Collector == nil {
+						t.Fatal("collector function is nil")
+					}
+				})
 			}
 
-			if task.Collector == nil {
-				t.Fatal("collector function is nil")
+			// Verify we have the expected number of collectors
+			if len(tasks) != count {
+				t.Errorf("expected %d collectors, got %d", count, len(tasks))
 			}
 		})
 	}
-
-	// Verify we have the expected number of collectors
-	if len(tasks) != len(expected) {
-		t.Errorf("expected %d collectors, got %d", len(expected), len(tasks))
-	}
 }
 
 // TestPostgreSQLTasksStructure verifies all PostgreSQL tasks have required fields
@@ -181,7 +230,7 @@ func TestPGQueryCollectorUnavailableAsSkip(t *testing.T) {
 			}
 			mock.ExpectQuery("SELECT").WillReturnError(tt.pgErr)
 
-			collector := pgQueryCollector(db, "SELECT 1")
+			collector := pgQueryCollector(db, "SELECT 1", nil)
 			err = collector(&Config{}, &bytes.Buffer{})
 
 			if err == nil {
@@ -195,3 +244,239 @@ func TestPGQueryCollectorUnavailableAsSkip(t *testing.T) {
 		})
 	}
 }
+
+// TestTopNPlaceholder verifies --top-n is substituted into per-relation queries
+func TestTopNPlaceholder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT relid FROM t LIMIT 25")).WillReturnRows(sqlmock.NewRows([]string{"relid"}).AddRow(1))
+
+	collector := pgQueryCollector(db, "SELECT relid FROM t LIMIT :top_n", nil)
+	if err := collector(&Config{TopN: 25}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("collector failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if got := expandQuery("LIMIT :top_n", &Config{}); got != "LIMIT 100" {
+		t.Errorf("expected the default limit, got %q", got)
+	}
+
+	// Every LIMIT of a per-database query follows --top-n
+	for _, tasks := range [][]SimpleQueryTask{perDatabaseQueryTasks, exactBloatQueryTasks} {
+		for _, task := range tasks {
+			if strings.Contains(task.Query, "LIMIT") && !strings.Contains(task.Query, TopNPlaceholder) {
+				t.Errorf("%s has a LIMIT without %s", task.Name, TopNPlaceholder)
+			}
+		}
+	}
+}
+
+// TestExactBloatTasks verifies pgstattuple_approx runs only with --exact-bloat
+func TestExactBloatTasks(t *testing.T) {
+	for _, exactBloat := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock: %v", err)
+		}
+		mock.ExpectQuery("SELECT datname FROM pg_database").
+			WillReturnRows(sqlmock.NewRows([]string{"datname"}).AddRow("template1").AddRow("app"))
+
+		tasks, err := generateDatabaseTasks(db, exactBloat)
+		closeErrCheck(db, "mock db")
+		if err != nil {
+			t.Fatalf("generateDatabaseTasks failed: %v", err)
+		}
+		paths := make(map[string]bool)
+		for _, task := range tasks {
+			paths[task.ArchivePath] = true
+		}
+		if !paths["databases/app/table_bloat.tsv"] || !paths["databases/app/index_bloat.tsv"] {
+			t.Errorf("exactBloat=%v: missing bloat estimates", exactBloat)
+		}
+		if paths["databases/app/table_bloat_exact.tsv"] != exactBloat {
+			t.Errorf("exactBloat=%v: table_bloat_exact.tsv collected = %v", exactBloat, !exactBloat)
+		}
+	}
+}
+
+// TestExactBloatRelations verifies pgstattuple_approx is not run on
+// temporary tables, nor on unlogged tables during recovery, where it fails
+func TestExactBloatRelations(t *testing.T) {
+	query := exactBloatQueryTasks[0].Query
+	if !strings.Contains(query, "(c.relpersistence = 'p' OR (c.relpersistence = 'u' AND NOT pg_is_in_recovery()))") {
+		t.Errorf("table_bloat_exact does not filter relations by persistence:\n%s", query)
+	}
+}
+
+// perDatabaseTask returns the per-database query task of a name
+func perDatabaseTask(t *testing.T, name string) SimpleQueryTask {
+	t.Helper()
+	for _, task := range perDatabaseQueryTasks {
+		if task.Name == name {
+			return task
+		}
+	}
+	t.Fatalf("per-database task %q not found", name)
+	return SimpleQueryTask{}
+}
+
+// perDatabasePaths returns the archive paths of the tasks generated for a
+// database named app
+func perDatabasePaths(t *testing.T) map[string]bool {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT datname FROM pg_database").
+		WillReturnRows(sqlmock.NewRows([]string{"datname"}).AddRow("app"))
+
+	tasks, err := generateDatabaseTasks(db, false)
+	if err != nil {
+		t.Fatalf("generateDatabaseTasks failed: %v", err)
+	}
+	paths := make(map[string]bool)
+	for _, task := range tasks {
+		paths[task.ArchivePath] = true
+	}
+	return paths
+}
+
+// expectTopN runs a query task and verifies its LIMIT follows --top-n
+func expectTopN(t *testing.T, task SimpleQueryTask) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT 7")).WillReturnRows(sqlmock.NewRows([]string{"schemaname"}).AddRow("public"))
+
+	if err := pgQueryCollector(db, task.Query, nil)(&Config{TopN: 7}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("%s failed: %v", task.Name, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("%s: %v", task.Name, err)
+	}
+}
+
+// TestIndexHealthTasks verifies the index health collectors run in every
+// database, follow --top-n and keep the conditions that select indexes
+func TestIndexHealthTasks(t *testing.T) {
+	paths := perDatabasePaths(t)
+	tests := []struct {
+		name       string
+		topN       bool
+		conditions []string
+	}{
+		// Exact duplicates keep the highest ranked index; prefixes are
+		// whole leading columns of another btree index
+		{"duplicate_indexes", true, []string{
+			"a.rank < b.rank OR (a.rank = b.rank AND a.indexrelid > b.indexrelid)",
+			"b.keys LIKE a.keys || ' %'",
+			"NOT a.indisunique",
+		}},
+		{"invalid_indexes", false, []string{"NOT i.indisvalid OR NOT i.indisready"}},
+		// The referencing columns lead a non-partial index, in any order
+		{"missing_fk_indexes", true, []string{
+			"(i.indkey::int2[])[0:cardinality(c.conkey) - 1] @> c.conkey",
+			"i.indpred IS NULL",
+		}},
+		{"unused_indexes", true, []string{
+			"s.idx_scan = 0",
+			"NOT i.indisunique",
+			"NOT i.indisprimary",
+			"c.conindid = i.indexrelid",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !paths["databases/app/"+tt.name+".tsv"] {
+				t.Errorf("%s is not collected per database", tt.name)
+			}
+			task := perDatabaseTask(t, tt.name)
+			for _, condition := range tt.conditions {
+				if !strings.Contains(task.Query, condition) {
+					t.Errorf("query lost %q", condition)
+				}
+			}
+			if tt.topN {
+				expectTopN(t, task)
+			}
+		})
+	}
+}
+
+// TestRelationSizeTasks verifies the size collectors run in every database,
+// follow --top-n and report raw byte counts
+func TestRelationSizeTasks(t *testing.T) {
+	paths := perDatabasePaths(t)
+	for _, name := range []string{"partition_tree_sizes", "relation_sizes"} {
+		if !paths["databases/app/"+name+".tsv"] {
+			t.Errorf("%s is not collected per database", name)
+		}
+		task := perDatabaseTask(t, name)
+		if !strings.Contains(task.Query, "AS total_bytes") || strings.Contains(task.Query, "pg_size_pretty") {
+			t.Errorf("%s does not report total_bytes as a number", name)
+		}
+		expectTopN(t, task)
+	}
+
+	for _, task := range postgresQueryTasks {
+		if task.Name == "database_sizes" || task.Name == "tablespace_sizes" {
+			if !strings.Contains(task.Query, "AS size_bytes") || !strings.Contains(task.Query, "ORDER BY size_bytes DESC") {
+				t.Errorf("%s does not report size_bytes: %s", task.Name, task.Query)
+			}
+		}
+	}
+}
+
+// TestServerRoleTasks verifies the role detection and the collectors chosen
+// for each role
+func TestServerRoleTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_is_in_recovery()")).WillReturnRows(sqlmock.NewRows([]string{"pg_is_in_recovery"}).AddRow(true))
+	if role, err := detectServerRole(db); err != nil || role != RoleStandby {
+		t.Errorf("expected %q, got %q (%v)", RoleStandby, role, err)
+	}
+
+	counts := func(role string) map[string]int {
+		names := make(map[string]int)
+		for _, task := range forServerRole(getPostgreSQLTasks(nil), role) {
+			names[task.Name]++
+		}
+		return names
+	}
+	tests := []struct {
+		role                                       string
+		walPosition, standbyLag, recoveryConflicts int
+	}{
+		{RolePrimary, 1, 0, 0},
+		{RoleStandby, 1, 1, 1},
+		{"", 0, 0, 0}, // Role-specific collectors need a known role
+	}
+	for _, tt := range tests {
+		names := counts(tt.role)
+		if names["wal_position"] != tt.walPosition || names["standby_lag"] != tt.standbyLag || names["recovery_conflicts"] != tt.recoveryConflicts {
+			t.Errorf("role %q: unexpected collectors wal_position=%d standby_lag=%d recovery_conflicts=%d",
+				tt.role, names["wal_position"], names["standby_lag"], names["recovery_conflicts"])
+		}
+		if names["activity"] != 1 {
+			t.Errorf("role %q: expected collectors of both roles", tt.role)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := serverRoleTask(RoleStandby).Collector(&Config{}, &buf); err != nil || buf.String() != "role\tin_recovery\nstandby\tt\n" {
+		t.Errorf("unexpected server_role.tsv %q (%v)", buf.String(), err)
+	}
+}
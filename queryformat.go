@@ -0,0 +1,254 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Output formats of query results (--query-format)
+const (
+	QueryFormatTSV    = "tsv"
+	QueryFormatCSV    = "csv"
+	QueryFormatNDJSON = "ndjson"
+)
+
+// ndjsonSchemaKey names the first NDJSON line, which describes the columns
+const ndjsonSchemaKey = "schema"
+
+// ndjsonColumn describes one column in the NDJSON schema line
+type ndjsonColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // PostgreSQL type name, "" if unknown
+	OID  uint32 `json:"oid"`  // PostgreSQL type OID, 0 if unknown
+}
+
+// pgTypes resolves PostgreSQL type names to OIDs for the NDJSON schema line
+var pgTypes = pgtype.NewMap()
+
+// validQueryFormat reports whether format is a supported --query-format.
+func validQueryFormat(format string) bool {
+	return format == QueryFormatTSV || format == QueryFormatCSV || format == QueryFormatNDJSON
+}
+
+// queryArchivePath returns the archive path of a query result written in
+// format: the .tsv extension of path is replaced by the format's.
+func queryArchivePath(path, format string) string {
+	if format == "" || format == QueryFormatTSV || !strings.HasSuffix(path, ".tsv") {
+		return path
+	}
+	return strings.TrimSuffix(path, ".tsv") + "." + format
+}
+
+// quoteCSVField applies the quoting of COPY ... CSV: fields containing a
+// comma, double quote or line break are quoted, as is the empty string so
+// that it differs from NULL.
+func quoteCSVField(s string) string {
+	if s == "" || strings.ContainsAny(s, ",\"\r\n") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
+
+// ndjsonSchema describes columns from the type names reported in the result
+// metadata. The driver reports types it does not know by OID. Repeated column
+// names get a numeric suffix, since they would repeat a key in every row.
+func ndjsonSchema(columns, typeNames []string) []ndjsonColumn {
+	schema := make([]ndjsonColumn, len(columns))
+	seen := make(map[string]bool)
+	for i, name := range columns {
+		base := name
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		seen[name] = true
+		schema[i].Name = name
+		if i >= len(typeNames) {
+			continue
+		}
+		if oid, err := strconv.ParseUint(typeNames[i], 10, 32); err == nil {
+			schema[i].OID = uint32(oid)
+			continue
+		}
+		schema[i].Type = strings.ToLower(typeNames[i])
+		if t, ok := pgTypes.TypeForName(schema[i].Type); ok {
+			schema[i].OID = t.OID
+		}
+	}
+	return schema
+}
+
+// writeNDJSONSchema writes the schema line of an NDJSON result.
+func writeNDJSONSchema(w io.Writer, schema []ndjsonColumn) error {
+	line, err := json.Marshal(map[string][]ndjsonColumn{ndjsonSchemaKey: schema})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// writeNDJSONRow writes one row as a JSON object with its keys in column order.
+func writeNDJSONRow(w io.Writer, schema []ndjsonColumn, values []sql.NullString) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, col := range schema {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := ndjsonValue(values[i], col.Type)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ndjsonNumericTypes are written as JSON numbers, keeping their text as is
+var ndjsonNumericTypes = map[string]bool{
+	"int2": true, "int4": true, "int8": true, "oid": true,
+	"float4": true, "float8": true, "numeric": true,
+}
+
+// ndjsonValue encodes a text value as JSON according to its column type. NULL
+// becomes null, numbers and booleans keep their JSON types (numeric without
+// loss of precision) and json and jsonb values are embedded, compacted to a
+// single line. Values without a JSON counterpart, such as NaN, are written as
+// strings.
+func ndjsonValue(v sql.NullString, typeName string) ([]byte, error) {
+	switch {
+	case !v.Valid:
+		return []byte("null"), nil
+	case ndjsonNumericTypes[typeName]:
+		if _, err := strconv.ParseFloat(v.String, 64); err == nil && json.Valid([]byte(v.String)) {
+			return []byte(v.String), nil
+		}
+	case typeName == "bool":
+		return []byte(strconv.FormatBool(isTrue(v.String))), nil
+	case typeName == "json" || typeName == "jsonb":
+		var buf bytes.Buffer
+		if json.Compact(&buf, []byte(v.String)) == nil {
+			return buf.Bytes(), nil
+		}
+	}
+	return json.Marshal(v.String)
+}
+
+// queryOutputToTSV converts a query result written in format to radar's TSV,
+// so that reports, rules and imports read every format alike. NULL and the
+// empty string both become an empty field, as in TSV output.
+func queryOutputToTSV(data []byte, format string) ([]byte, error) {
+	var t *tsvTable
+	var err error
+	switch format {
+	case QueryFormatCSV:
+		t, err = parseCSV(data)
+	case QueryFormatNDJSON:
+		t, err = parseNDJSON(data)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.writeTSV(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseCSV parses a CSV query result; the first line is the header.
+func parseCSV(data []byte) (*tsvTable, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV")
+	}
+	return &tsvTable{Columns: records[0], Rows: records[1:]}, nil
+}
+
+// parseNDJSON parses an NDJSON query result: a schema line followed by one
+// object per row.
+func parseNDJSON(data []byte) (*tsvTable, error) {
+	lines := splitLines(string(data))
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty NDJSON")
+	}
+	var header map[string][]ndjsonColumn
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		return nil, fmt.Errorf("line 1: %w", err)
+	}
+	schema, ok := header[ndjsonSchemaKey]
+	if !ok {
+		return nil, fmt.Errorf("line 1: missing %s", ndjsonSchemaKey)
+	}
+
+	t := &tsvTable{Columns: make([]string, len(schema))}
+	for i, col := range schema {
+		t.Columns[i] = col.Name
+	}
+	for n, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+2, err)
+		}
+		row := make([]string, len(schema))
+		for i, col := range schema {
+			row[i] = ndjsonText(obj[col.Name])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// ndjsonText renders a JSON value as a TSV field: strings unquoted, null
+// empty, booleans as t/f, and numbers and embedded JSON as written.
+func ndjsonText(raw json.RawMessage) string {
+	switch string(raw) {
+	case "", "null":
+		return ""
+	case "true":
+		return "t"
+	case "false":
+		return "f"
+	}
+	var s string
+	if raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,153 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestWriteRowsQueryFormats verifies CSV and NDJSON rendering of a typed result set
+func TestWriteRowsQueryFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+		detail   string // The JSON value read back
+	}{
+		{
+			format: QueryFormatCSV,
+			detail: `{"a": [1]}`,
+			expected: "sample_ts,pid,state,ratio,detail\n" +
+				"T1,1,active,1.50,\"{\"\"a\"\": [1]}\"\n" +
+				"T1,2,\"\",,\n",
+		},
+		{
+			format: QueryFormatNDJSON,
+			detail: `{"a":[1]}`,
+			expected: `{"schema":[{"name":"sample_ts","type":"text","oid":25},{"name":"pid","type":"int4","oid":23},{"name":"state","type":"text","oid":25},{"name":"ratio","type":"numeric","oid":1700},{"name":"detail","type":"jsonb","oid":3802}]}` + "\n" +
+				`{"sample_ts":"T1","pid":1,"state":"active","ratio":1.50,"detail":{"a":[1]}}` + "\n" +
+				`{"sample_ts":"T1","pid":2,"state":"","ratio":null,"detail":null}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer closeErrCheck(db, "mock db")
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("pid").OfType("INT4", int64(0)),
+				sqlmock.NewColumn("state").OfType("TEXT", ""),
+				sqlmock.NewColumn("ratio").OfType("NUMERIC", ""),
+				sqlmock.NewColumn("detail").OfType("JSONB", []byte{}),
+			).AddRow(int64(1), "active", "1.50", []byte(`{"a": [1]}`)).AddRow(int64(2), "", nil, nil))
+
+			var buf bytes.Buffer
+			opts := tsvOptions{Header: true, PrefixName: SampleTimestampColumn, PrefixValue: "T1", Format: tt.format}
+			if err := pgQueryToTSV(db, "SELECT", &buf, opts); err != nil {
+				t.Fatalf("pgQueryToTSV failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+
+			// Analysis reads every format as TSV
+			converted, err := queryOutputToTSV(buf.Bytes(), tt.format)
+			if err != nil {
+				t.Fatalf("queryOutputToTSV failed: %v", err)
+			}
+			table, err := parseTSV(converted)
+			if err != nil {
+				t.Fatalf("parseTSV failed: %v", err)
+			}
+			if len(table.Rows) != 2 || table.Rows[0][4] != tt.detail || table.Rows[1][3] != "" {
+				t.Errorf("unexpected converted table %q", table.Rows)
+			}
+		})
+	}
+}
+
+// TestNDJSONFraming verifies multi-line json values and repeated column names
+// keep one row per line and every column
+func TestNDJSONFraming(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("oid").OfType("INT4", int64(0)),
+		sqlmock.NewColumn("oid").OfType("INT4", int64(0)),
+		sqlmock.NewColumn("doc").OfType("JSON", []byte{}),
+	).AddRow(int64(1), int64(2), []byte("{\n  \"a\": [1,\n 2]\n}")))
+
+	var buf bytes.Buffer
+	if err := pgQueryToTSV(db, "SELECT", &buf, tsvOptions{Header: true, Format: QueryFormatNDJSON}); err != nil {
+		t.Fatalf("pgQueryToTSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[1] != `{"oid":1,"oid_2":2,"doc":{"a":[1,2]}}` {
+		t.Fatalf("unexpected NDJSON:\n%s", buf.String())
+	}
+
+	table, err := parseNDJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parseNDJSON failed: %v", err)
+	}
+	if len(table.Rows) != 1 || table.Rows[0][0] != "1" || table.Rows[0][1] != "2" {
+		t.Errorf("unexpected table %q %q", table.Columns, table.Rows)
+	}
+}
+
+// TestQueryArchivePath verifies the archive extension follows the query format
+func TestQueryArchivePath(t *testing.T) {
+	tests := []struct {
+		path, format, expected string
+	}{
+		{"postgresql/activity.tsv", QueryFormatTSV, "postgresql/activity.tsv"},
+		{"postgresql/activity.tsv", "", "postgresql/activity.tsv"},
+		{"postgresql/activity.tsv", QueryFormatCSV, "postgresql/activity.csv"},
+		{"databases/app/tables.tsv", QueryFormatNDJSON, "databases/app/tables.ndjson"},
+	}
+	for _, tt := range tests {
+		if got := queryArchivePath(tt.path, tt.format); got != tt.expected {
+			t.Errorf("queryArchivePath(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.expected)
+		}
+	}
+}
+
+// TestLoadArchiveQueryFormats verifies CSV and NDJSON entries load as TSV tables
+func TestLoadArchiveQueryFormats(t *testing.T) {
+	archivePath := writeTestArchive(t, map[string]string{
+		"postgresql/configuration.ndjson": `{"schema":[{"name":"name","type":"text","oid":25},{"name":"setting","type":"text","oid":25}]}` + "\n" +
+			`{"name":"work_mem","setting":"4096"}` + "\n",
+		"databases/app/tables.csv":           "schemaname,tablename\npublic,orders\n",
+		"postgresql/logs/postgresql-Thu.csv": "2026-01-15 13:37:00.000 UTC,,,42,,6789.0,1,,2026-01-15 13:00:00 UTC,,0,LOG,00000,\"checkpoint starting: time\",,,,,,,,,\"\",checkpointer,,0\n",
+	})
+	a, err := loadArchive(archivePath)
+	if err != nil {
+		t.Fatalf("loadArchive failed: %v", err)
+	}
+	if v, ok := a.value("postgresql/configuration.tsv", "setting"); !ok || v != "4096" {
+		t.Errorf("expected setting 4096, got %q, %v", v, ok)
+	}
+	if v, ok := a.value("databases/app/tables.tsv", "tablename"); !ok || v != "orders" {
+		t.Errorf("expected tablename orders, got %q, %v", v, ok)
+	}
+	if _, ok := a.Files["postgresql/logs/postgresql-Thu.csv"]; !ok {
+		t.Error("expected the csvlog file to be kept as is")
+	}
+}
@@ -12,12 +12,14 @@ package main
 
 import (
 	"archive/zip"
+	"bytes"
 	"database/sql"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"os/user"
@@ -40,6 +42,16 @@ const (
 // Archive Settings
 const DefaultCompressionMethod = zip.Deflate
 
+// Sampling Defaults
+const DefaultSampleInterval = time.Second
+
+// Row limit of the per-relation and statement collectors (--top-n), substituted for the
+// TopNPlaceholder in their queries
+const (
+	DefaultTopN     = 100
+	TopNPlaceholder = ":top_n"
+)
+
 // Error message patterns for skip detection
 var (
 	ExecutableNotFoundPatterns = []string{"executable file not found", "command not found"}
@@ -75,6 +87,50 @@ type Config struct {
 	SkipPostgres bool
 	Verbose      bool
 	VeryVerbose  bool
+
+	// Repeated sampling of volatile PostgreSQL views
+	Samples  int
+	Interval time.Duration
+
+	// Interval between the two counter snapshots of --delta (0 = disabled)
+	Delta time.Duration
+
+	// Crossed thresholds that started this collection (daemon mode)
+	TriggerReasons []TriggerReason
+
+	// Outcome of every collection task, written to manifest.tsv
+	Results []CollectionResult
+
+	// Copies of the archive entries read by the health rules
+	Captured map[string][]byte
+
+	// Output format of the main collection (zip or sqlite)
+	Format string
+
+	// Output format of query results (tsv, csv or ndjson)
+	QueryFormat string
+
+	// Also write the unmodified SELECT * output of stable schema collectors
+	RawSelect bool
+
+	// Row limit of the per-table, per-index and per-function collectors
+	TopN int
+
+	// Also measure table bloat with pgstattuple_approx
+	ExactBloat bool
+
+	// Server log files modified within this window are collected (0 = none)
+	LogWindow time.Duration
+
+	// MB collected from the end of each server log file
+	LogMaxSize int
+
+	// PostgreSQL column types of each TSV entry, recorded for --format sqlite
+	ColumnTypes map[string][]string
+
+	// Server role (primary or standby) detected when the collection starts,
+	// empty if it could not be detected
+	Role string
 }
 
 // CollectionTask defines a single data collection task
@@ -83,6 +139,10 @@ type CollectionTask struct {
 	Name        string // Descriptive name for logging
 	ArchivePath string // Path within ZIP archive
 	Collector   func(*Config, io.Writer) error
+	QueryResult bool          // Output is a result set written in the --query-format
+	Schema      *OutputSchema // Stable schema of the output, recorded in the manifest
+	Raw         bool          // Unmodified SELECT * output, collected only with --raw-select
+	Role        string        // Collected only on a server of this role; empty for both
 }
 
 // lazyZipWriter defers ZIP entry creation until first Write()
@@ -113,6 +173,37 @@ func (w *lazyZipWriter) WroteAny() bool {
 	return w.writer != nil
 }
 
+// columnTypeWriter is implemented by collector writers that record the
+// PostgreSQL column types of the TSV written to them
+type columnTypeWriter interface {
+	io.Writer
+	SetColumnTypes(types []string)
+}
+
+// taskWriter is the writer handed to a collector. It writes to the archive
+// entry, keeps a copy if the health rules need one and records column types.
+type taskWriter struct {
+	lazy        *lazyZipWriter
+	captured    *bytes.Buffer // nil unless the entry is a health rule input
+	columnTypes []string
+}
+
+// Write writes to the archive entry and the captured copy.
+func (w *taskWriter) Write(p []byte) (int, error) {
+	n, err := w.lazy.Write(p)
+	if w.captured != nil {
+		w.captured.Write(p[:n])
+	}
+	return n, err
+}
+
+// SetColumnTypes records the column types of the first result set written.
+func (w *taskWriter) SetColumnTypes(types []string) {
+	if w.columnTypes == nil {
+		w.columnTypes = types
+	}
+}
+
 // SkipError indicates a collector was skipped (tool missing, no data, not applicable)
 type SkipError struct {
 	Reason string
@@ -187,8 +278,24 @@ var (
 	errorLog = log.New(os.Stderr, "ERROR: ", 0)
 )
 
+// subcommands maps the first argument to an alternative entry point
+var subcommands = map[string]func(args []string) int{
+	"daemon": runDaemon,
+	"check":  runCheck,
+	"diff":   runDiff,
+	"import": runImport,
+	"report": runReport,
+	"serve":  runServe,
+}
+
 // main is the radar entry point.
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
 	cfg, err := parseConfig()
 	if err != nil {
 		errorLog.Println(err)
@@ -197,12 +304,7 @@ func main() {
 	}
 
 	// Generate output filename
-	hostname, _ := os.Hostname()
-	if hostname == "" {
-		hostname = "unknown"
-	}
-	timestamp := time.Now().Format(TimestampFormat)
-	outputFile := fmt.Sprintf("radar-%s-%s.zip", hostname, timestamp)
+	outputFile := archiveName(time.Now())
 
 	// Simplified output for non-verbose mode
 	if !cfg.Verbose {
@@ -227,14 +329,45 @@ func main() {
 	}
 
 	// Create output file (don't announce it unless verbose)
+	write := writeArchive
+	if cfg.Format == FormatSQLite {
+		outputFile = strings.TrimSuffix(outputFile, ".zip") + ".sqlite"
+		write = writeSQLiteBundle
+	}
 	if cfg.Verbose {
 		infoLog.Printf("Creating archive: %s", outputFile)
 	}
-	outFile, err := os.Create(outputFile)
+	totalCollected, err := write(cfg, outputFile)
 	if err != nil {
-		errorLog.Printf("Failed to create output file: %v", err)
+		errorLog.Println(err)
 		os.Exit(ExitCollectError)
 	}
+
+	if totalCollected == 0 {
+		errorLog.Println("No data collected - this may indicate a problem")
+		os.Exit(ExitNoData)
+	}
+
+	// Print summary
+	printSummary(totalCollected, outputFile, cfg)
+}
+
+// archiveName returns the archive filename for a collection started at t.
+func archiveName(t time.Time) string {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("radar-%s-%s.zip", hostname, t.Format(TimestampFormat))
+}
+
+// writeArchive runs all collection tasks into a new ZIP archive at path.
+// Returns the number of collectors that produced output.
+func writeArchive(cfg *Config, path string) (int, error) {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
 	defer closeErrCheck(outFile, "output file")
 
 	// Create ZIP writer
@@ -244,21 +377,25 @@ func main() {
 	if cfg.Verbose {
 		infoLog.Println("Starting data collection...")
 	}
+	cfg.Captured = make(map[string][]byte)
 	totalCollected := collectAll(cfg, zipWriter)
 
-	// Close ZIP writer
-	if err := zipWriter.Close(); err != nil {
-		errorLog.Printf("Failed to close archive: %v", err)
-		os.Exit(ExitCollectError)
+	// Evaluate the health rules against what was collected
+	if err := writeFindings(cfg, zipWriter); err != nil {
+		errorLog.Printf("Failed to write findings: %v", err)
 	}
 
-	if totalCollected == 0 {
-		errorLog.Println("No data collected - this may indicate a problem")
-		os.Exit(ExitNoData)
+	// Record the outcome of every collector
+	if err := writeManifest(cfg, zipWriter); err != nil {
+		errorLog.Printf("Failed to write manifest: %v", err)
 	}
 
-	// Print summary
-	printSummary(totalCollected, outputFile, cfg)
+	// Close ZIP writer
+	if err := zipWriter.Close(); err != nil {
+		return totalCollected, fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return totalCollected, nil
 }
 
 // parseConfig parses command-line flags into a Config.
@@ -266,30 +403,57 @@ func parseConfig() (*Config, error) {
 	cfg := &Config{}
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: radar [options]\n\nOptions:\n")
+		fmt.Fprintf(os.Stderr, "Usage: radar [options]\n       radar daemon [options]\n       radar report [options] <archive.zip>\n       radar check [options] <archive.zip>\n       radar diff [options] <before.zip> <after.zip>\n       radar serve [options]\n       radar import [options] <archive.zip> --into <connection string>\n\nOptions:\n")
 		flag.PrintDefaults()
 	}
 
-	flag.StringVar(&cfg.Host, "h", "", "database host")
-	flag.IntVar(&cfg.Port, "p", DefaultPostgresPort, "database port")
-	flag.StringVar(&cfg.Database, "d", "", "database name")
-	flag.StringVar(&cfg.Username, "U", "", "database user")
-	flag.StringVar(&cfg.DataDir, "data-dir", "", "PostgreSQL data directory")
-	flag.StringVar(&cfg.SSLMode, "sslmode", "prefer", "SSL mode (prefer, disable, require, verify-ca, verify-full)")
-	flag.StringVar(&cfg.SSLCert, "sslcert", "", "client SSL certificate file")
-	flag.StringVar(&cfg.SSLKey, "sslkey", "", "client SSL key file")
-	flag.StringVar(&cfg.SSLRootCert, "sslrootcert", "", "SSL root certificate file")
-	flag.BoolVar(&cfg.SkipSystem, "skip-system", false, "skip system data collection")
-	flag.BoolVar(&cfg.SkipPostgres, "skip-postgres", false, "skip PostgreSQL data collection")
-	flag.BoolVar(&cfg.Verbose, "v", false, "verbose output (summary)")
-	flag.BoolVar(&cfg.VeryVerbose, "vv", false, "very verbose output (detailed)")
+	registerConfigFlags(flag.CommandLine, cfg)
+	flag.StringVar(&cfg.Format, "format", FormatZIP, "output format (zip, sqlite)")
 	flag.Parse()
 
+	if cfg.Format != FormatZIP && cfg.Format != FormatSQLite {
+		return nil, fmt.Errorf("invalid --format %q: must be zip or sqlite", cfg.Format)
+	}
+	return finishConfig(flag.CommandLine, cfg)
+}
+
+// registerConfigFlags defines the connection and collection flags on fs.
+func registerConfigFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.StringVar(&cfg.Host, "h", "", "database host")
+	fs.IntVar(&cfg.Port, "p", DefaultPostgresPort, "database port")
+	fs.StringVar(&cfg.Database, "d", "", "database name")
+	fs.StringVar(&cfg.Username, "U", "", "database user")
+	fs.StringVar(&cfg.DataDir, "data-dir", "", "PostgreSQL data directory")
+	fs.StringVar(&cfg.SSLMode, "sslmode", "prefer", "SSL mode (prefer, disable, require, verify-ca, verify-full)")
+	fs.StringVar(&cfg.SSLCert, "sslcert", "", "client SSL certificate file")
+	fs.StringVar(&cfg.SSLKey, "sslkey", "", "client SSL key file")
+	fs.StringVar(&cfg.SSLRootCert, "sslrootcert", "", "SSL root certificate file")
+	fs.BoolVar(&cfg.SkipSystem, "skip-system", false, "skip system data collection")
+	fs.BoolVar(&cfg.SkipPostgres, "skip-postgres", false, "skip PostgreSQL data collection")
+	fs.BoolVar(&cfg.Verbose, "v", false, "verbose output (summary)")
+	fs.BoolVar(&cfg.VeryVerbose, "vv", false, "very verbose output (detailed)")
+	fs.IntVar(&cfg.Samples, "samples", 0, "number of samples to take of volatile PostgreSQL views (0 = single snapshot)")
+	fs.DurationVar(&cfg.Interval, "interval", DefaultSampleInterval, "interval between samples")
+	fs.DurationVar(&cfg.Delta, "delta", 0, "take two counter snapshots this far apart and write per-second rates (0 = disabled)")
+	fs.StringVar(&cfg.QueryFormat, "query-format", QueryFormatTSV, "output format of query results (tsv, csv, ndjson)")
+	fs.IntVar(&cfg.TopN, "top-n", DefaultTopN, "maximum rows of the per-table, per-index, per-function and pg_stat_statements collectors")
+	fs.DurationVar(&cfg.LogWindow, "log-window", DefaultLogWindow, "collect the server log files modified within this window (0 = no server logs)")
+	fs.IntVar(&cfg.LogMaxSize, "log-max-size", DefaultLogMaxSize, "maximum MB collected from the end of each server log file")
+	fs.BoolVar(&cfg.ExactBloat, "exact-bloat", false, "also measure the bloat of the largest tables with pgstattuple_approx (reads the tables)")
+	fs.BoolVar(&cfg.RawSelect, "raw-select", false, "also write the unmodified SELECT * output of collectors with a stable schema as *_raw.tsv")
+}
+
+// finishConfig applies environment fallbacks and validates a parsed Config.
+func finishConfig(fs *flag.FlagSet, cfg *Config) (*Config, error) {
 	// If -vv is set, also enable -v
 	if cfg.VeryVerbose {
 		cfg.Verbose = true
 	}
 
+	if !validQueryFormat(cfg.QueryFormat) {
+		return nil, fmt.Errorf("invalid --query-format %q: must be tsv, csv or ndjson", cfg.QueryFormat)
+	}
+
 	// Environment variable fallbacks
 	if cfg.Host == "" {
 		cfg.Host = os.Getenv("PGHOST")
@@ -299,7 +463,7 @@ func parseConfig() (*Config, error) {
 	}
 
 	portFlagSet := false
-	flag.Visit(func(f *flag.Flag) {
+	fs.Visit(func(f *flag.Flag) {
 		if f.Name == "p" {
 			portFlagSet = true
 		}
@@ -331,7 +495,7 @@ func parseConfig() (*Config, error) {
 
 	if !cfg.SkipPostgres {
 		sslmodeFlagSet := false
-		flag.Visit(func(f *flag.Flag) {
+		fs.Visit(func(f *flag.Flag) {
 			if f.Name == "sslmode" {
 				sslmodeFlagSet = true
 			}
@@ -380,6 +544,29 @@ func parseConfig() (*Config, error) {
 		}
 	}
 
+	// Validate sampling options
+	if cfg.Samples < 0 {
+		return nil, fmt.Errorf("--samples must not be negative")
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("--interval must be positive")
+	}
+	if cfg.Samples > 0 && cfg.SkipPostgres {
+		return nil, fmt.Errorf("--samples requires PostgreSQL data collection")
+	}
+	if cfg.Delta < 0 {
+		return nil, fmt.Errorf("--delta must not be negative")
+	}
+	if cfg.TopN < 1 {
+		return nil, fmt.Errorf("--top-n must be at least 1")
+	}
+	if cfg.LogWindow < 0 {
+		return nil, fmt.Errorf("--log-window must not be negative")
+	}
+	if cfg.LogMaxSize < 1 {
+		return nil, fmt.Errorf("--log-max-size must be at least 1")
+	}
+
 	// Validate skip flag combinations
 	if cfg.SkipSystem && cfg.SkipPostgres {
 		return nil, fmt.Errorf("cannot use --skip-system and --skip-postgres together (nothing would be collected)")
@@ -434,6 +621,54 @@ func (c *Config) ConnectionString(dbname string) string {
 	return strings.Join(params, " ")
 }
 
+// isLocalHost reports whether the PostgreSQL host is this machine: a unix
+// socket directory, a loopback address, or a name or address of a local
+// interface. With several hosts, all of them must be local.
+func isLocalHost(host string) bool {
+	var local []net.IP
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				local = append(local, ipNet.IP)
+			}
+		}
+	}
+	isLocalIP := func(ip net.IP) bool {
+		if ip.IsLoopback() {
+			return true
+		}
+		for _, l := range local {
+			if l.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, h := range strings.Split(host, ",") {
+		h = strings.TrimSpace(h)
+		switch {
+		case h == "" || strings.HasPrefix(h, "/") || strings.HasPrefix(h, "@") || h == "localhost":
+			continue
+		case net.ParseIP(h) != nil:
+			if !isLocalIP(net.ParseIP(h)) {
+				return false
+			}
+		default:
+			ips, err := net.LookupIP(h)
+			if err != nil || len(ips) == 0 {
+				return false
+			}
+			for _, ip := range ips {
+				if !isLocalIP(ip) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
 // initPostgreSQL opens and verifies the PostgreSQL connection.
 func initPostgreSQL(cfg *Config) error {
 	db, err := sql.Open("pgx", cfg.ConnectionString(cfg.Database))
@@ -463,14 +698,52 @@ func collectAll(cfg *Config, zipWriter *zip.Writer) int {
 	}
 
 	if !cfg.SkipPostgres {
+		// Detect the role once per collection (the daemon may follow a
+		// failover) to choose the primary or standby collectors
+		role, err := detectServerRole(cfg.DB)
+		if err != nil {
+			errorLog.Printf("Failed to detect server role: %v", err)
+		} else {
+			pgTasks = append(pgTasks, serverRoleTask(role))
+		}
+		cfg.Role = role
+
 		// Pass cfg.DB to PostgreSQL task generators
 		pgTasks = append(pgTasks, getPostgreSQLTasks(cfg.DB)...)
-		dbTasks, err := generateDatabaseTasks(cfg.DB)
+		dbTasks, err := generateDatabaseTasks(cfg.DB, cfg.ExactBloat)
 		if err != nil {
 			errorLog.Printf("Failed to generate database tasks: %v", err)
 		} else {
 			pgTasks = append(pgTasks, dbTasks...)
 		}
+		logTasks, err := generateLogTasks(cfg)
+		if err != nil {
+			errorLog.Printf("Failed to locate server logs: %v", err)
+		} else {
+			pgTasks = append(pgTasks, logTasks...)
+		}
+		if !cfg.RawSelect {
+			pgTasks = withoutRawTasks(pgTasks)
+		}
+		pgTasks = forServerRole(pgTasks, cfg.Role)
+	}
+
+	// Record why a triggered collection was taken
+	if len(cfg.TriggerReasons) > 0 {
+		collected += collect(cfg, zipWriter, []CollectionTask{triggerTask(cfg.TriggerReasons)})
+	}
+
+	// Take the first counter snapshot before anything else adds load
+	var delta *deltaRun
+	if cfg.Delta > 0 {
+		delta = startDelta(cfg)
+	}
+
+	// PHASE 0: Repeatedly sample volatile PostgreSQL views, which are then
+	// excluded from the one-time collection below
+	if !cfg.SkipPostgres && cfg.Samples > 0 {
+		collected += collectSamples(cfg, zipWriter)
+		pgTasks = withoutVolatileTasks(pgTasks)
 	}
 
 	// PHASE 1: Collect system tasks
@@ -483,6 +756,11 @@ func collectAll(cfg *Config, zipWriter *zip.Writer) int {
 		collected += collect(cfg, zipWriter, pgTasks)
 	}
 
+	// PHASE 3: Take the second counter snapshot and write rates
+	if delta != nil {
+		collected += delta.finish(cfg, zipWriter)
+	}
+
 	return collected
 }
 
@@ -490,10 +768,15 @@ func collectAll(cfg *Config, zipWriter *zip.Writer) int {
 // Returns: collected count only
 func collect(cfg *Config, zipWriter *zip.Writer, tasks []CollectionTask) int {
 	collected := 0
+	ruleInputs := healthRuleInputs()
 
 	for _, task := range tasks {
+		archivePath, format := task.ArchivePath, QueryFormatTSV
+		if task.QueryResult {
+			archivePath, format = queryArchivePath(archivePath, cfg.QueryFormat), cfg.QueryFormat
+		}
 		header := &zip.FileHeader{
-			Name:     task.ArchivePath,
+			Name:     archivePath,
 			Method:   DefaultCompressionMethod,
 			Modified: time.Now(),
 		}
@@ -501,33 +784,65 @@ func collect(cfg *Config, zipWriter *zip.Writer, tasks []CollectionTask) int {
 		// Use lazy writer - only creates ZIP entry on first Write()
 		lazy := &lazyZipWriter{zipWriter: zipWriter, header: header}
 
-		err := task.Collector(cfg, lazy)
-		if err != nil {
+		// Keep a copy of entries the health rules will read
+		w := &taskWriter{lazy: lazy}
+		if cfg.Captured != nil && ruleInputs[task.ArchivePath] {
+			w.captured = &bytes.Buffer{}
+		}
+
+		started := time.Now()
+		err := task.Collector(cfg, w)
+		result := CollectionResult{
+			Category:    task.Category,
+			Name:        task.Name,
+			ArchivePath: archivePath,
+			Duration:    time.Since(started),
+		}
+		if task.Schema != nil {
+			result.SchemaVersion = task.Schema.Version
+		}
+
+		switch {
+		case err != nil:
 			var skipErr SkipError
 			if errors.As(err, &skipErr) {
 				// Unavailable (command not found, file missing, no data)
+				result.Status = StatusSkipped
 				if cfg.VeryVerbose {
 					infoLog.Printf("⊘ %s (unavailable)", task.Name)
 				}
 			} else {
 				// Error (I/O, permission, SQL)
+				result.Status = StatusError
 				errorLog.Printf("✗ %s: %v", task.Name, err)
 			}
-			continue
-		}
+			result.Error = err.Error()
 
-		// Only count if something was actually written
-		if !lazy.WroteAny() {
+		case !lazy.WroteAny():
+			// Only count if something was actually written
+			result.Status = StatusEmpty
 			if cfg.VeryVerbose {
 				infoLog.Printf("⊘ %s (empty)", task.Name)
 			}
-			continue
-		}
 
-		if cfg.VeryVerbose {
-			infoLog.Printf("✓ %s", task.Name)
+		default:
+			result.Status = StatusOK
+			if w.captured != nil {
+				// The health rules read query results as TSV
+				captured, err := queryOutputToTSV(w.captured.Bytes(), format)
+				if err == nil {
+					cfg.Captured[task.ArchivePath] = captured
+				}
+			}
+			if cfg.ColumnTypes != nil && w.columnTypes != nil {
+				cfg.ColumnTypes[task.ArchivePath] = w.columnTypes
+			}
+			if cfg.VeryVerbose {
+				infoLog.Printf("✓ %s", task.Name)
+			}
+			collected++
 		}
-		collected++
+		cfg.Results = append(cfg.Results, result)
 	}
 
 	return collected
@@ -606,91 +921,127 @@ func readFileCollector(path string) func(*Config, io.Writer) error {
 	}
 }
 
-// pgQueryCollector creates a collector that executes a PostgreSQL query and streams results as TSV
-func pgQueryCollector(db *sql.DB, query string) func(*Config, io.Writer) error {
+// pgQueryCollector creates a collector that executes a PostgreSQL query and streams results in the --query-format,
+// projected onto schema unless it is nil
+func pgQueryCollector(db *sql.DB, query string, schema *OutputSchema) func(*Config, io.Writer) error {
 	return func(cfg *Config, w io.Writer) error {
-		if db == nil {
-			return fmt.Errorf("PostgreSQL not initialized")
-		}
-		rows, err := db.Query(query)
-		if err != nil {
-			if isPGUnavailableError(err) {
-				return NewSkipError(err.Error())
-			}
-			return err
-		}
-		defer closeErrCheck(rows, "query rows")
-		return rowsToTSV(rows, w)
+		return pgQueryToTSV(db, expandQuery(query, cfg), w, tsvOptions{Header: true, Format: cfg.QueryFormat, Schema: schema})
+	}
+}
+
+// expandQuery substitutes the collection settings referenced by a query.
+func expandQuery(query string, cfg *Config) string {
+	topN := cfg.TopN
+	if topN < 1 {
+		topN = DefaultTopN
 	}
+	return strings.ReplaceAll(query, TopNPlaceholder, strconv.Itoa(topN))
+}
+
+// pgQueryToTSV executes a PostgreSQL query and streams results as TSV
+func pgQueryToTSV(db *sql.DB, query string, w io.Writer, opts tsvOptions) error {
+	if db == nil {
+		return fmt.Errorf("PostgreSQL not initialized")
+	}
+	return queryTextRows(db, query, func(rows textRows) error {
+		return writeTextRows(rows, w, opts)
+	})
+}
+
+// tsvOptions controls how writeRowsTSV renders a result set
+type tsvOptions struct {
+	Header      bool          // Write the column header line (the schema line for NDJSON)
+	PrefixName  string        // Optional leading column added to every row
+	PrefixValue string        // Value of the leading column
+	Format      string        // tsv (default), csv or ndjson
+	Schema      *OutputSchema // Stable column list to project the result onto
 }
 
 // rowsToTSV streams SQL rows to TSV format directly to writer
 func rowsToTSV(rows *sql.Rows, w io.Writer) error {
-	// Get column names
-	columns, err := rows.Columns()
+	return writeRowsTSV(rows, w, tsvOptions{Header: true})
+}
+
+// writeRowsTSV streams database/sql rows to TSV, or to CSV or NDJSON as
+// selected by opts.Format, optionally prefixing a constant column
+func writeRowsTSV(rows *sql.Rows, w io.Writer, opts tsvOptions) error {
+	r, err := newSQLTextRows(rows)
 	if err != nil {
-		return fmt.Errorf("getting columns: %w", err)
+		return err
 	}
+	return writeTextRows(r, w, opts)
+}
 
-	// Write TSV header
-	for i, col := range columns {
-		if i > 0 {
-			if _, err := w.Write([]byte{'\t'}); err != nil {
-				return err
+// writeTextRows streams a result set rendered as PostgreSQL text values
+func writeTextRows(rows textRows, w io.Writer, opts tsvOptions) error {
+	if opts.Schema != nil {
+		rows = opts.Schema.project(rows)
+	}
+	columns, types := rows.Columns(), rows.ColumnTypes()
+	if opts.PrefixName != "" {
+		columns = append([]string{opts.PrefixName}, columns...)
+		types = append([]string{"TEXT"}, types...)
+	}
+
+	// Record column types for writers that keep them (--format sqlite)
+	if tw, ok := w.(columnTypeWriter); ok {
+		tw.SetColumnTypes(types)
+	}
+
+	separator, quote := "\t", quoteTSVField
+	if opts.Format == QueryFormatCSV {
+		separator, quote = ",", quoteCSVField
+	}
+	var schema []ndjsonColumn
+	if opts.Format == QueryFormatNDJSON {
+		schema = ndjsonSchema(columns, types)
+	}
+
+	// Write the header
+	switch {
+	case !opts.Header:
+	case schema != nil:
+		if err := writeNDJSONSchema(w, schema); err != nil {
+			return err
+		}
+	default:
+		fields := columns
+		if opts.Format == QueryFormatCSV {
+			fields = make([]string, len(columns))
+			for i, col := range columns {
+				fields[i] = quote(col)
 			}
 		}
-		if _, err := io.WriteString(w, col); err != nil {
+		if _, err := io.WriteString(w, strings.Join(fields, separator)+"\n"); err != nil {
 			return err
 		}
 	}
-	if _, err := w.Write([]byte{'\n'}); err != nil {
-		return err
-	}
-
-	// Prepare scan destinations
-	values := make([]interface{}, len(columns))
-	valuePtrs := make([]interface{}, len(columns))
-	for i := range values {
-		valuePtrs[i] = &values[i]
-	}
 
 	// Write rows
 	for rows.Next() {
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return fmt.Errorf("scanning row: %w", err)
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+		if opts.PrefixName != "" {
+			values = append([]sql.NullString{{String: opts.PrefixValue, Valid: true}}, values...)
 		}
 
-		for i, val := range values {
-			if i > 0 {
-				if _, err := w.Write([]byte{'\t'}); err != nil {
-					return err
-				}
-			}
-
-			if val == nil {
-				// NULL → empty string
-				continue
-			}
-
-			// Convert to string
-			var str string
-			switch v := val.(type) {
-			case []byte:
-				str = string(v)
-			default:
-				str = fmt.Sprintf("%v", v)
-			}
-
-			// TSV escaping: quote if contains tab, newline, or quote
-			if strings.ContainsAny(str, "\t\n\r\"") {
-				str = `"` + strings.ReplaceAll(str, `"`, `""`) + `"`
+		if schema != nil {
+			if err := writeNDJSONRow(w, schema, values); err != nil {
+				return err
 			}
+			continue
+		}
 
-			if _, err := io.WriteString(w, str); err != nil {
-				return err
+		fields := make([]string, len(values))
+		for i, v := range values {
+			// NULL → empty field
+			if v.Valid {
+				fields[i] = quote(v.String)
 			}
 		}
-		if _, err := w.Write([]byte{'\n'}); err != nil {
+		if _, err := io.WriteString(w, strings.Join(fields, separator)+"\n"); err != nil {
 			return err
 		}
 	}
@@ -15,6 +15,7 @@ import (
 	"bytes"
 	"flag"
 	"io"
+	"net"
 	"os"
 	"strings"
 	"testing"
@@ -311,6 +312,36 @@ func TestConnectionString(t *testing.T) {
 	}
 }
 
+// Test isLocalHost accepts unix sockets, loopback addresses and the addresses
+// of local interfaces
+func TestIsLocalHost(t *testing.T) {
+	hosts := map[string]bool{
+		"":                    true,
+		"/var/run/postgresql": true,
+		"localhost":           true,
+		"127.0.0.1":           true,
+		"::1":                 true,
+		"/tmp,127.0.0.1":      true,
+		"192.0.2.1":           false,
+		"/tmp,192.0.2.1":      false,
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		t.Fatalf("listing interface addresses: %v", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			hosts[ipNet.IP.String()] = true
+			break
+		}
+	}
+	for host, expected := range hosts {
+		if got := isLocalHost(host); got != expected {
+			t.Errorf("isLocalHost(%q) = %v, expected %v", host, got, expected)
+		}
+	}
+}
+
 // Test getSystemTasks returns valid tasks
 func TestGetSystemTasks(t *testing.T) {
 	tasks := getSystemTasks()
@@ -490,15 +521,18 @@ func TestNoDuplicateSystemArchivePaths(t *testing.T) {
 	}
 }
 
-// TestNoDuplicatePostgreSQLArchivePaths verifies no duplicate archive paths in PostgreSQL tasks
+// TestNoDuplicatePostgreSQLArchivePaths verifies no duplicate archive paths in
+// the PostgreSQL tasks of each server role
 func TestNoDuplicatePostgreSQLArchivePaths(t *testing.T) {
-	tasks := getPostgreSQLTasks(nil)
-	seen := make(map[string]string)
-	for _, task := range tasks {
-		if prev, exists := seen[task.ArchivePath]; exists {
-			t.Errorf("duplicate archive path %q: %q and %q", task.ArchivePath, prev, task.Name)
+	for _, role := range []string{RolePrimary, RoleStandby} {
+		tasks := forServerRole(getPostgreSQLTasks(nil), role)
+		seen := make(map[string]string)
+		for _, task := range tasks {
+			if prev, exists := seen[task.ArchivePath]; exists {
+				t.Errorf("%s: duplicate archive path %q: %q and %q", role, task.ArchivePath, prev, task.Name)
+			}
+			seen[task.ArchivePath] = task.Name
 		}
-		seen[task.ArchivePath] = task.Name
 	}
 }
 
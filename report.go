@@ -0,0 +1,621 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Report Limits
+const (
+	ReportMaxRows       = 50  // Rows shown per table
+	ReportMaxQueryChars = 300 // Statement text shown per row
+	ReportTopStatements = 20
+	ReportChartWidth    = 640
+	ReportChartHeight   = 160
+)
+
+// reportKeySettings are the pg_settings shown in the PostgreSQL overview
+var reportKeySettings = []string{
+	"autovacuum", "checkpoint_timeout", "data_checksums", "effective_cache_size",
+	"huge_pages", "maintenance_work_mem", "max_connections", "max_wal_senders",
+	"max_wal_size", "random_page_cost", "shared_buffers", "shared_preload_libraries",
+	"wal_level", "work_mem",
+}
+
+// reportMeminfoFields are the /proc/meminfo lines shown in the host overview
+var reportMeminfoFields = []string{
+	"MemTotal", "MemAvailable", "Buffers", "Cached", "Dirty", "Shmem",
+	"SwapTotal", "SwapFree", "HugePages_Total", "HugePages_Free", "Hugepagesize",
+}
+
+// reportSection is one top-level section of the HTML report
+type reportSection struct {
+	ID     string
+	Title  string
+	Blocks []reportBlock
+}
+
+// reportBlock is a titled table, preformatted text or chart within a section
+type reportBlock struct {
+	Title string
+	Note  string
+	Text  string
+	Table *tsvTable
+	Chart template.HTML
+}
+
+// runReport implements "radar report": render an archive as a single HTML file.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("radar report", flag.ContinueOnError)
+	output := fs.String("o", "", "output HTML file (default: archive name with .html)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: radar report [options] <archive.zip>\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	archivePath, err := parseArchiveArgs(fs, args, 1)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		errorLog.Println(err)
+		fs.Usage()
+		return ExitUsageError
+	}
+
+	a, err := loadArchive(archivePath[0])
+	if err != nil {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(archivePath[0], ".zip") + ".html"
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		errorLog.Printf("failed to create report: %v", err)
+		return ExitCollectError
+	}
+	if err := writeReport(a, f); err != nil {
+		closeErrCheck(f, "report")
+		errorLog.Printf("failed to write report: %v", err)
+		return ExitCollectError
+	}
+	if err := f.Close(); err != nil {
+		errorLog.Printf("failed to write report: %v", err)
+		return ExitCollectError
+	}
+
+	infoLog.Printf("✓ Report created: %s", out)
+	return 0
+}
+
+// parseArchiveArgs parses subcommand flags that may appear before or after
+// the archive arguments, and returns exactly n archive paths.
+func parseArchiveArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var archives []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		archives = append(archives, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(archives) != n {
+		return nil, fmt.Errorf("expected %d archive argument(s), got %d", n, len(archives))
+	}
+	return archives, nil
+}
+
+// writeReport renders the HTML report of an archive.
+func writeReport(a *radarArchive, w io.Writer) error {
+	data := struct {
+		Archive   string
+		Collected string
+		Generated string
+		Sections  []reportSection
+	}{
+		Archive:   path.Base(a.Path),
+		Generated: time.Now().Format(time.RFC3339),
+		Sections: []reportSection{
+			hostSection(a),
+			postgresSection(a),
+			activitySection(a),
+			replicationSection(a),
+			statementsSection(a),
+			logsSection(a),
+			statvizSection(a),
+			collectorSection(a),
+		},
+	}
+	if !a.Collected.IsZero() {
+		data.Collected = a.Collected.Format(time.RFC3339)
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+// hostSection summarizes the hardware, memory, OS and filesystems.
+func hostSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "host", Title: "Host Overview"}
+	s.addText("CPU (lscpu)", a.text("system/lscpu.out"))
+
+	if meminfo := a.text("system/proc/meminfo.out"); meminfo != "" {
+		values := make(map[string]string)
+		for _, line := range strings.Split(meminfo, "\n") {
+			if name, value, ok := strings.Cut(line, ":"); ok {
+				values[name] = strings.TrimSpace(value)
+			}
+		}
+		t := &tsvTable{Columns: []string{"field", "value"}}
+		for _, name := range reportMeminfoFields {
+			if v, ok := values[name]; ok {
+				t.Rows = append(t.Rows, []string{name, v})
+			}
+		}
+		s.addTable("Memory (/proc/meminfo)", t)
+	}
+
+	s.addText("Operating System", a.text("system/os_release.out"))
+	s.addText("Filesystems (df)", a.text("system/diskspace.out"))
+	return s
+}
+
+// postgresSection summarizes version, uptime, settings and database sizes.
+func postgresSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "postgresql", Title: "PostgreSQL Overview"}
+
+	overview := &tsvTable{Columns: []string{"item", "value"}}
+	if v, ok := a.value("postgresql/version.tsv", "version"); ok {
+		overview.Rows = append(overview.Rows, []string{"version", v})
+	}
+	if v, ok := a.value(ServerRolePath, "role"); ok {
+		overview.Rows = append(overview.Rows, []string{"role", v})
+	}
+	if v, ok := a.value("postgresql/postmaster_start_time.tsv", "start_time"); ok {
+		overview.Rows = append(overview.Rows, []string{"started", v})
+		if started, ok := parseArchiveTimestamp(v); ok && !a.Collected.IsZero() {
+			overview.Rows = append(overview.Rows, []string{"uptime", formatUptime(a.Collected.Sub(started))})
+		}
+	}
+	s.addTable("Instance", overview)
+
+	if t, ok := a.table("postgresql/configuration.tsv"); ok {
+		keep := make(map[string]bool)
+		for _, name := range reportKeySettings {
+			keep[name] = true
+		}
+		s.addTable("Key Settings", filterRows(t, "name", keep))
+	}
+	if t, ok := a.table("postgresql/file_settings.tsv"); ok {
+		s.addTable("Settings From Configuration Files", t)
+	}
+	if t, ok := a.table("postgresql/database_sizes.tsv"); ok {
+		s.addTable("Database Sizes", t)
+	}
+	return s
+}
+
+// activitySection shows connections, waits, blocking and long-running activity.
+func activitySection(a *radarArchive) reportSection {
+	s := reportSection{ID: "activity", Title: "Activity and Locks"}
+	for _, f := range []struct{ title, name string }{
+		{"Connection Summary", "postgresql/connection_summary.tsv"},
+		{"Blocking Locks", "postgresql/blocking_locks.tsv"},
+		{"Wait Events", "postgresql/waits_sample.tsv"},
+		{"Longest Running Activity", "postgresql/running_activity_maxage.tsv"},
+		{"Prepared Transactions", "postgresql/prepared_xacts.tsv"},
+	} {
+		if t, ok := a.table(f.name); ok {
+			s.addTable(f.title, t)
+		}
+	}
+	return s
+}
+
+// replicationSection shows senders, slots and the receiver.
+func replicationSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "replication", Title: "Replication"}
+	for _, f := range []struct{ title, name string }{
+		{"Topology", "postgresql/replication_topology.tsv"},
+		{"WAL Position", "postgresql/wal_position.tsv"},
+		{"Standby Lag", "postgresql/replication_lag.tsv"},
+		{"Replication (pg_stat_replication)", "postgresql/replication.tsv"},
+		{"Slot Lag", "postgresql/replication_slot_lag.tsv"},
+		{"Replication Slots", "postgresql/replication_slots.tsv"},
+		{"Slot Statistics", "postgresql/replication_slot_stats.tsv"},
+		{"Receive and Replay Lag", "postgresql/standby_lag.tsv"},
+		{"Recovery Status", "postgresql/recovery_status.tsv"},
+		{"Recovery Conflicts", "postgresql/recovery_conflicts.tsv"},
+		{"WAL Receiver", "postgresql/wal_receiver.tsv"},
+		{"Subscriptions", "postgresql/subscriptions.tsv"},
+	} {
+		if t, ok := a.table(f.name); ok {
+			s.addTable(f.title, t)
+		}
+	}
+	return s
+}
+
+// reportStatementColumns are the pg_stat_statements columns shown in the
+// report, under their names in every extension version
+var reportStatementColumns = []string{
+	"datname", "rolname", "calls", "total_exec_time", "total_time", "mean_exec_time", "mean_time",
+	"max_exec_time", "max_time", "total_plan_time", "rows", "shared_blks_hit", "shared_blks_read",
+	"temp_blks_written", "wal_bytes", "query",
+}
+
+// statementsSection shows the top pg_stat_statements entries.
+func statementsSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "statements", Title: "Top Statements"}
+	if t, ok := a.table(StatementsInfoPath); ok {
+		s.addTable("pg_stat_statements", t)
+	}
+	for _, name := range a.glob("postgresql/stat_statements_*.tsv") {
+		t, ok := a.table(name)
+		if !ok || name == StatementsInfoPath {
+			continue
+		}
+		if len(t.Rows) > ReportTopStatements {
+			t = &tsvTable{Columns: t.Columns, Rows: t.Rows[:ReportTopStatements]}
+		}
+		// Archives with the database names have every column of the view
+		if t.columnIndex("datname") >= 0 {
+			t = projectTable(t, reportStatementColumns)
+		}
+		if q := t.columnIndex("query"); q >= 0 {
+			for _, row := range t.Rows {
+				if q < len(row) {
+					row[q] = truncateText(row[q], ReportMaxQueryChars)
+				}
+			}
+		}
+		title := strings.ReplaceAll(strings.TrimSuffix(path.Base(name), ".tsv"), "stat_statements_", "by ")
+		s.addTable(strings.ReplaceAll(title, "_", " "), t)
+	}
+	return s
+}
+
+// logsSection shows the analysis of the collected server logs.
+func logsSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "logs", Title: "Server Logs"}
+	for _, f := range []struct{ title, name string }{
+		{"Overview", "summary"},
+		{"Errors by SQLSTATE", "errors"},
+		{"Connection Failures", "connection_failures"},
+		{"Slowest Statements", "slow_statements"},
+		{"Lock Waits and Deadlocks", "lock_waits"},
+		{"Temporary Files", "temp_files"},
+		{"Autovacuum", "autovacuum"},
+		{"Checkpoints", "checkpoints"},
+	} {
+		t, ok := a.table(LogAnalysisDir + "/" + f.name + ".tsv")
+		if !ok {
+			continue
+		}
+		if q := t.columnIndex("statement"); q >= 0 {
+			for _, row := range t.Rows {
+				if q < len(row) {
+					row[q] = truncateText(row[q], ReportMaxQueryChars)
+				}
+			}
+		}
+		s.addTable(f.title, t)
+	}
+	if len(s.Blocks) == 0 {
+		s.Blocks = append(s.Blocks, reportBlock{Note: "No server logs in this archive."})
+	}
+	return s
+}
+
+// statvizSection charts every numeric pg_statviz column over its snapshots.
+func statvizSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "statviz", Title: "pg_statviz Time Series"}
+	for _, name := range a.glob("pg_statviz/*/*.tsv") {
+		t, ok := a.table(name)
+		if !ok {
+			continue
+		}
+		ts := t.columnIndex("snapshot_tstamp")
+		if ts < 0 || len(t.Rows) < 2 {
+			continue
+		}
+
+		var charts strings.Builder
+		for i, col := range t.Columns {
+			if i == ts {
+				continue
+			}
+			if chart, ok := lineChartSVG(col, t, ts, i); ok {
+				charts.WriteString(chart)
+			}
+		}
+		if charts.Len() > 0 {
+			s.Blocks = append(s.Blocks, reportBlock{
+				Title: strings.TrimPrefix(strings.TrimSuffix(name, ".tsv"), "pg_statviz/"),
+				Chart: template.HTML(charts.String()),
+			})
+		}
+	}
+	return s
+}
+
+// collectorSection summarizes manifest.tsv, listing failed collectors first.
+func collectorSection(a *radarArchive) reportSection {
+	s := reportSection{ID: "collectors", Title: "Collector Status"}
+	t, ok := a.table(ManifestPath)
+	if !ok {
+		s.Blocks = append(s.Blocks, reportBlock{Note: "No manifest.tsv in this archive (collected by an older radar)."})
+		return s
+	}
+
+	statusCol := t.columnIndex("status")
+	if statusCol < 0 {
+		s.Blocks = append(s.Blocks, reportBlock{Title: "All Collectors", Table: t})
+		return s
+	}
+	status := func(row []string) string {
+		if statusCol < len(row) {
+			return row[statusCol]
+		}
+		return ""
+	}
+	counts := make(map[string]int)
+	for _, row := range t.Rows {
+		counts[status(row)]++
+	}
+	summary := &tsvTable{Columns: []string{"status", "collectors"}}
+	for _, status := range []string{StatusOK, StatusSkipped, StatusEmpty, StatusError} {
+		summary.Rows = append(summary.Rows, []string{status, strconv.Itoa(counts[status])})
+	}
+	s.addTable("Summary", summary)
+
+	rank := map[string]int{StatusError: 0, StatusSkipped: 1, StatusEmpty: 2, StatusOK: 3}
+	sorted := &tsvTable{Columns: t.Columns, Rows: append([][]string{}, t.Rows...)}
+	sort.SliceStable(sorted.Rows, func(i, j int) bool {
+		return rank[status(sorted.Rows[i])] < rank[status(sorted.Rows[j])]
+	})
+	s.Blocks = append(s.Blocks, reportBlock{Title: "All Collectors", Table: sorted})
+	return s
+}
+
+// addText appends a preformatted block if the text is not empty.
+func (s *reportSection) addText(title, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	s.Blocks = append(s.Blocks, reportBlock{Title: title, Text: text})
+}
+
+// addTable appends a table, truncated to ReportMaxRows, if it has rows.
+func (s *reportSection) addTable(title string, t *tsvTable) {
+	if t == nil || len(t.Rows) == 0 {
+		return
+	}
+	block := reportBlock{Title: title, Table: t}
+	if len(t.Rows) > ReportMaxRows {
+		block.Table = &tsvTable{Columns: t.Columns, Rows: t.Rows[:ReportMaxRows]}
+		block.Note = fmt.Sprintf("Showing %d of %d rows.", ReportMaxRows, len(t.Rows))
+	}
+	s.Blocks = append(s.Blocks, block)
+}
+
+// filterRows keeps the rows whose column value is in keep.
+func filterRows(t *tsvTable, column string, keep map[string]bool) *tsvTable {
+	result := &tsvTable{Columns: t.Columns}
+	i := t.columnIndex(column)
+	if i < 0 {
+		return result
+	}
+	for _, row := range t.Rows {
+		if i < len(row) && keep[row[i]] {
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	return result
+}
+
+// projectTable keeps the listed columns that t has, in the listed order.
+func projectTable(t *tsvTable, columns []string) *tsvTable {
+	var indexes []int
+	result := &tsvTable{}
+	for _, name := range columns {
+		if i := t.columnIndex(name); i >= 0 {
+			indexes = append(indexes, i)
+			result.Columns = append(result.Columns, name)
+		}
+	}
+	for _, row := range t.Rows {
+		projected := make([]string, len(indexes))
+		for j, i := range indexes {
+			if i < len(row) {
+				projected[j] = row[i]
+			}
+		}
+		result.Rows = append(result.Rows, projected)
+	}
+	return result
+}
+
+// truncateText shortens s to at most n characters, marking the cut with an
+// ellipsis. It never splits a multibyte character.
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n]) + "…"
+}
+
+// formatUptime renders a duration as days, hours and minutes.
+func formatUptime(d time.Duration) string {
+	if d < 0 {
+		return ""
+	}
+	days := int(d.Hours()) / 24
+	return fmt.Sprintf("%dd %dh %dm", days, int(d.Hours())%24, int(d.Minutes())%60)
+}
+
+// lineChartSVG renders one numeric column against the timestamp column as an
+// inline SVG line chart. It reports false if the column is not numeric.
+func lineChartSVG(title string, t *tsvTable, tsCol, valueCol int) (string, bool) {
+	type point struct {
+		t time.Time
+		v float64
+	}
+	var points []point
+	for _, row := range t.Rows {
+		if tsCol >= len(row) || valueCol >= len(row) || row[valueCol] == "" {
+			continue
+		}
+		ts, ok := parseArchiveTimestamp(row[tsCol])
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(row[valueCol], 64)
+		if err != nil {
+			return "", false
+		}
+		points = append(points, point{ts, v})
+	}
+	if len(points) < 2 {
+		return "", false
+	}
+
+	minT, maxT := points[0].t, points[len(points)-1].t
+	minV, maxV := points[0].v, points[0].v
+	for _, p := range points {
+		minV, maxV = math.Min(minV, p.v), math.Max(maxV, p.v)
+	}
+	span := maxT.Sub(minT).Seconds()
+	if span <= 0 {
+		return "", false
+	}
+	if maxV == minV {
+		maxV = minV + 1
+	}
+
+	const left, right, top, bottom = 70, 10, 24, 22
+	plotW := float64(ReportChartWidth - left - right)
+	plotH := float64(ReportChartHeight - top - bottom)
+
+	var coords []string
+	for _, p := range points {
+		x := left + p.t.Sub(minT).Seconds()/span*plotW
+		y := top + (1-(p.v-minV)/(maxV-minV))*plotH
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img">`,
+		ReportChartWidth, ReportChartHeight, ReportChartWidth, ReportChartHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="14" class="title">%s</text>`, left, html.EscapeString(title))
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%.0f" class="axis"/>`, left, top, left, top+plotH)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.0f" x2="%.0f" y2="%.0f" class="axis"/>`, left, top+plotH, left+plotW, top+plotH)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end">%s</text>`, left-4, top+4, formatChartValue(maxV))
+	fmt.Fprintf(&b, `<text x="%d" y="%.0f" class="label" text-anchor="end">%s</text>`, left-4, top+plotH, formatChartValue(minV))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label">%s</text>`, left, ReportChartHeight-6, minT.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, `<text x="%.0f" y="%d" class="label" text-anchor="end">%s</text>`, left+plotW, ReportChartHeight-6, maxT.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, `<polyline points="%s" class="line"/>`, strings.Join(coords, " "))
+	b.WriteString(`</svg>`)
+	return b.String(), true
+}
+
+// formatChartValue renders an axis label compactly, e.g. 1.5M.
+func formatChartValue(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e12:
+		return strconv.FormatFloat(v/1e12, 'f', 1, 64) + "T"
+	case abs >= 1e9:
+		return strconv.FormatFloat(v/1e9, 'f', 1, 64) + "G"
+	case abs >= 1e6:
+		return strconv.FormatFloat(v/1e6, 'f', 1, 64) + "M"
+	case abs >= 1e3:
+		return strconv.FormatFloat(v/1e3, 'f', 1, 64) + "k"
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// reportTemplate is the self-contained report page: inline CSS, no scripts or external assets
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>radar report: {{.Archive}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #1d2330; }
+header { background: #1d2330; color: #fff; padding: 16px 24px; }
+header h1 { margin: 0 0 4px; font-size: 20px; }
+header p { margin: 0; color: #b8c0cc; font-size: 13px; }
+nav { padding: 8px 24px; border-bottom: 1px solid #dde1e7; font-size: 14px; }
+nav a { margin-right: 16px; color: #0b6bcb; text-decoration: none; }
+main { padding: 0 24px 24px; }
+h2 { border-bottom: 2px solid #1d2330; padding-bottom: 4px; margin-top: 32px; }
+h3 { font-size: 15px; margin: 20px 0 8px; }
+table { border-collapse: collapse; font-size: 12px; margin-bottom: 8px; }
+th, td { border: 1px solid #dde1e7; padding: 3px 6px; text-align: left; vertical-align: top; }
+th { background: #f2f4f7; }
+td { white-space: pre-wrap; max-width: 480px; overflow-wrap: anywhere; }
+pre { background: #f6f8fa; border: 1px solid #dde1e7; padding: 8px; font-size: 12px; overflow-x: auto; }
+.note, .empty { color: #5b6472; font-size: 13px; }
+.chart { margin: 4px 12px 12px 0; border: 1px solid #dde1e7; background: #fff; }
+.chart .title { font-size: 12px; font-weight: bold; }
+.chart .label { font-size: 10px; fill: #5b6472; }
+.chart .axis { stroke: #9aa3af; stroke-width: 1; }
+.chart .line { fill: none; stroke: #0b6bcb; stroke-width: 1.5; }
+</style>
+</head>
+<body>
+<header>
+<h1>radar report</h1>
+<p>{{.Archive}}{{if .Collected}} &middot; collected {{.Collected}}{{end}} &middot; generated {{.Generated}}</p>
+</header>
+<nav>{{range .Sections}}<a href="#{{.ID}}">{{.Title}}</a>{{end}}</nav>
+<main>
+{{range .Sections}}
+<section id="{{.ID}}">
+<h2>{{.Title}}</h2>
+{{if not .Blocks}}<p class="empty">No data in this archive.</p>{{end}}
+{{range .Blocks}}
+{{if .Title}}<h3>{{.Title}}</h3>{{end}}
+{{if .Text}}<pre>{{.Text}}</pre>{{end}}
+{{with .Table}}<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+{{if .Chart}}<div>{{.Chart}}</div>{{end}}
+{{if .Note}}<p class="note">{{.Note}}</p>{{end}}
+{{end}}
+</section>
+{{end}}
+</main>
+</body>
+</html>
+`))
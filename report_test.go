@@ -0,0 +1,211 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// writeTestArchive creates a ZIP archive with the given entries in a temp dir
+func writeTestArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "radar-test.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Modified: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+// TestCollectRecordsResults verifies collect records every outcome for manifest.tsv
+func TestCollectRecordsResults(t *testing.T) {
+	write := func(_ *Config, w io.Writer) error {
+		_, err := io.WriteString(w, "data\n")
+		return err
+	}
+	tasks := []CollectionTask{
+		{Category: "system", Name: "ok", ArchivePath: "ok.out", Collector: write},
+		{Category: "system", Name: "skipped", ArchivePath: "skipped.out", Collector: func(*Config, io.Writer) error { return NewSkipError("missing") }},
+		{Category: "system", Name: "failed", ArchivePath: "failed.out", Collector: func(*Config, io.Writer) error { return errors.New("boom") }},
+		{Category: "system", Name: "empty", ArchivePath: "empty.out", Collector: func(*Config, io.Writer) error { return nil }},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	cfg := &Config{}
+	collect(cfg, zw, tasks)
+	if err := writeManifest(cfg, zw); err != nil {
+		t.Fatalf("writeManifest failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"ok": StatusOK, "skipped": StatusSkipped, "failed": StatusError, "empty": StatusEmpty}
+	if len(cfg.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(cfg.Results))
+	}
+	for _, r := range cfg.Results {
+		if r.Status != expected[r.Name] {
+			t.Errorf("%s: expected status %q, got %q", r.Name, expected[r.Name], r.Status)
+		}
+	}
+	if cfg.Results[2].Error != "boom" {
+		t.Errorf("expected error text to be recorded, got %q", cfg.Results[2].Error)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range reader.File {
+		if f.Name == ManifestPath {
+			return
+		}
+	}
+	t.Error("manifest.tsv not found in archive")
+}
+
+// TestWriteReport verifies sections, escaping, charts and the absence of external assets
+func TestWriteReport(t *testing.T) {
+	archivePath := writeTestArchive(t, map[string]string{
+		"system/proc/meminfo.out":                "MemTotal:       16384000 kB\nMemFree: 1 kB\n",
+		"postgresql/version.tsv":                 "version\nPostgreSQL 17.2\n",
+		"postgresql/server_role.tsv":             "role\tin_recovery\nstandby\tt\n",
+		"postgresql/postmaster_start_time.tsv":   "start_time\n2026-03-01 12:00:00+00\n",
+		"postgresql/configuration.tsv":           "name\tsetting\nshared_buffers\t16384\nzero_damaged_pages\toff\n",
+		"postgresql/stat_statements_calls.tsv":   "query\tcalls\n\"SELECT '<script>'\"\t42\n",
+		"postgresql/replication_topology.tsv":    "kind\tname\taddress\tstate\tdetail\nnode\tprimary\t\t\t\ndownstream\treplica1\t10.0.0.2\tstreaming\tasync\n",
+		"postgresql/stat_statements_rows.tsv":    "datname\trolname\tuserid\tdbid\tqueryid\tquery\trows\nshop\tapp\t10\t5\t-4711\tSELECT 1\t1234567\n",
+		"postgresql/stat_statements_info.tsv":    "database\tschema\textversion\tmax\ttrack\tdealloc\tstats_reset\nshop\tpublic\t1.10\t5000\ttop\t12\t\n",
+		"pg_statviz/app/conn.tsv":                "snapshot_tstamp\tconn_total\tconn_users\n2026-03-01 10:00:00+00\t10\t{}\n2026-03-01 11:00:00+00\t30\t{}\n",
+		ManifestPath:                             "category\tname\tpath\tstatus\tduration_ms\terror\nsystem\tlscpu\tsystem/lscpu.out\terror\t1.0\tfailed\n",
+		"postgresql/running_activity_maxage.tsv": "pid\n1\n",
+		"postgresql/log_analysis/errors.tsv":     "severity\tsqlstate\tcount\tfirst_seen\tlast_seen\tmessage\nFATAL\t28P01\t7\t\t\tpassword authentication failed\n",
+	})
+
+	a, err := loadArchive(archivePath)
+	if err != nil {
+		t.Fatalf("loadArchive failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeReport(a, &buf); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"MemTotal", "PostgreSQL 17.2", "1d 0h 0m", "shared_buffers", "&lt;script&gt;",
+		"<svg", "conn_total", "Longest Running Activity", "<td>error</td>",
+		"Errors by SQLSTATE", "<td>28P01</td>", "by rows", "<td>1234567</td>", "<td>5000</td>", "Topology", "<td>replica1</td>", "<td>standby</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"zero_damaged_pages", "<script>", "conn_users</text>", "src=", "href=\"http", "-4711", "by info"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("report unexpectedly contains %q", unwanted)
+		}
+	}
+}
+
+// TestTruncateText verifies long text is cut on a character boundary
+func TestTruncateText(t *testing.T) {
+	long := strings.Repeat("a", ReportMaxQueryChars-1) + "ééé"
+	got := truncateText(long, ReportMaxQueryChars)
+	if !utf8.ValidString(got) || got != strings.Repeat("a", ReportMaxQueryChars-1)+"é…" {
+		t.Errorf("unexpected truncation %q", got[len(got)-8:])
+	}
+	if got := truncateText("SELECT 1", ReportMaxQueryChars); got != "SELECT 1" {
+		t.Errorf("short text changed to %q", got)
+	}
+}
+
+// TestLogsSectionTruncatesStatements verifies long multibyte statements stay valid UTF-8
+func TestLogsSectionTruncatesStatements(t *testing.T) {
+	statement := "SELECT '" + strings.Repeat("ü", ReportMaxQueryChars) + "'"
+	archivePath := writeTestArchive(t, map[string]string{
+		LogAnalysisDir + "/slow_statements.tsv": "log_time\tuser\tdatabase\tduration_ms\tstatement\n\tapp\tshop\t1500.000\t" + statement + "\n",
+	})
+	a, err := loadArchive(archivePath)
+	if err != nil {
+		t.Fatalf("loadArchive failed: %v", err)
+	}
+	s := logsSection(a)
+	if len(s.Blocks) != 1 || s.Blocks[0].Table == nil {
+		t.Fatalf("expected the slow statements table, got %+v", s.Blocks)
+	}
+	got := s.Blocks[0].Table.Rows[0][4]
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) != ReportMaxQueryChars+1 {
+		t.Errorf("unexpected truncated statement %q", got)
+	}
+}
+
+// TestCollectorSectionWithoutStatus verifies a manifest without a status
+// column is shown as is
+func TestCollectorSectionWithoutStatus(t *testing.T) {
+	archivePath := writeTestArchive(t, map[string]string{
+		ManifestPath: "category\tname\npostgresql\tversion\nsystem\n",
+	})
+	a, err := loadArchive(archivePath)
+	if err != nil {
+		t.Fatalf("loadArchive failed: %v", err)
+	}
+	s := collectorSection(a)
+	if len(s.Blocks) != 1 || s.Blocks[0].Table == nil || len(s.Blocks[0].Table.Rows) != 2 {
+		t.Errorf("expected the raw manifest table, got %+v", s.Blocks)
+	}
+}
+
+// TestParseArchiveArgs verifies flags are accepted before and after archive arguments
+func TestParseArchiveArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	output := fs.String("o", "", "")
+
+	archives, err := parseArchiveArgs(fs, []string{"a.zip", "-o", "out.html"}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archives[0] != "a.zip" || *output != "out.html" {
+		t.Errorf("unexpected parse: %q, %q", archives, *output)
+	}
+
+	if _, err := parseArchiveArgs(fs, []string{"a.zip", "b.zip"}, 1); err == nil {
+		t.Error("expected error for extra archive argument")
+	}
+}
@@ -0,0 +1,524 @@
+//go:build linux
+
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Native Sampler Defaults
+const (
+	NativeSampleInterval = time.Second // Interval of the commands, e.g. "iostat -x 1 5"
+	DiskSectorBytes      = 512         // /proc/diskstats always counts 512-byte sectors
+)
+
+// SimpleSamplerTask defines an interval statistics collector. The external
+// command is used when it is installed; otherwise the text output is rendered
+// from native /proc samples, unless the native report covers only part of
+// the command output. The native report is always written as TSV.
+type SimpleSamplerTask struct {
+	Name        string
+	ArchivePath string // Text output of the command or the native report
+	TSVPath     string // Native report as TSV
+	Command     string
+	Args        []string
+	Count       int  // Intervals reported, as in Args
+	CommandOnly bool // The native report is not written as the text output
+	Report      func([]procSnapshot) (*tsvTable, error)
+}
+
+// procSnapshot holds one reading of the /proc files used by the samplers
+type procSnapshot struct {
+	taken   time.Time
+	stat    *procStat
+	disks   *tsvTable
+	vmstat  map[string]float64
+	meminfo map[string]float64
+	netdev  *tsvTable
+	nfs     *tsvTable
+}
+
+// procStat holds the parts of /proc/stat used by the samplers
+type procStat struct {
+	cpus         [][]float64 // user nice system idle iowait irq softirq steal guest guest_nice
+	cpuNames     []string    // "all" for the aggregate line, then cpu0, cpu1, ...
+	intr         float64
+	ctxt         float64
+	procsRunning float64
+	procsBlocked float64
+}
+
+// procSampler takes the native samples once, on first use, for all sampler tasks
+type procSampler struct {
+	once      sync.Once
+	count     int
+	interval  time.Duration
+	snapshots []procSnapshot
+}
+
+// samples returns the snapshots, taking them on the first call.
+func (s *procSampler) samples() []procSnapshot {
+	s.once.Do(func() {
+		start := time.Now()
+		for i := 0; i <= s.count; i++ {
+			if i > 0 {
+				time.Sleep(time.Until(start.Add(time.Duration(i) * s.interval)))
+			}
+			s.snapshots = append(s.snapshots, readProcSnapshot())
+		}
+	})
+	return s.snapshots
+}
+
+// getSamplerTasks builds the text and TSV tasks of the interval samplers
+func getSamplerTasks() []CollectionTask {
+	sampler := &procSampler{interval: NativeSampleInterval}
+	for _, t := range systemSamplerTasks {
+		sampler.count = max(sampler.count, t.Count)
+	}
+
+	var result []CollectionTask
+	for _, t := range systemSamplerTasks {
+		result = append(result,
+			CollectionTask{
+				Category:    "system",
+				Name:        t.Name,
+				ArchivePath: t.ArchivePath,
+				Collector:   samplerTextCollector(t, sampler),
+			},
+			CollectionTask{
+				Category:    "system",
+				Name:        t.Name + "-native",
+				ArchivePath: t.TSVPath,
+				Collector: func(cfg *Config, w io.Writer) error {
+					table, err := t.report(sampler)
+					if err != nil {
+						return err
+					}
+					return table.writeTSV(w)
+				},
+			},
+		)
+	}
+	return result
+}
+
+// report computes the native report over the first t.Count intervals.
+func (t SimpleSamplerTask) report(sampler *procSampler) (*tsvTable, error) {
+	snaps := sampler.samples()
+	if len(snaps) > t.Count+1 {
+		snaps = snaps[:t.Count+1]
+	}
+	return t.Report(snaps)
+}
+
+// samplerTextCollector runs the external command if installed and falls back
+// to rendering the native report when it is missing or has no data.
+func samplerTextCollector(t SimpleSamplerTask, sampler *procSampler) func(*Config, io.Writer) error {
+	return func(cfg *Config, w io.Writer) error {
+		if _, err := exec.LookPath(t.Command); err == nil {
+			data, err := execCommand(t.Command, t.Args...)
+			if err == nil {
+				_, err = w.Write(data)
+				return err
+			}
+			var skipErr SkipError
+			if !errors.As(err, &skipErr) {
+				return err
+			}
+		}
+
+		if t.CommandOnly {
+			return NewSkipError(fmt.Sprintf("command not found: %s", t.Command))
+		}
+		table, err := t.report(sampler)
+		if err != nil {
+			return err
+		}
+		return renderSamplerText(t.Name, t.Count, table, w)
+	}
+}
+
+// renderSamplerText writes a native report as an aligned text table.
+func renderSamplerText(name string, count int, table *tsvTable, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintf(tw, "# %s (native, %d x %s from /proc)\n", name, count, NativeSampleInterval); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(tw, strings.Join(table.Columns, "\t")+"\t"); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")+"\t"); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// readProcSnapshot reads all sampler sources. Unreadable sources are left nil
+// and the reports depending on them are skipped.
+func readProcSnapshot() procSnapshot {
+	snap := procSnapshot{taken: time.Now()}
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		snap.stat = parseProcStat(string(data))
+	}
+	if data, err := os.ReadFile("/proc/diskstats"); err == nil {
+		snap.disks = parseDiskstats(string(data))
+	}
+	if data, err := os.ReadFile("/proc/vmstat"); err == nil {
+		snap.vmstat = parseNameValues(string(data))
+	}
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		snap.meminfo = parseNameValues(string(data))
+	}
+	if data, err := os.ReadFile("/proc/net/dev"); err == nil {
+		snap.netdev = parseNetDev(string(data))
+	}
+	if data, err := os.ReadFile("/proc/self/mountstats"); err == nil {
+		snap.nfs = parseNFSMountstats(string(data))
+	}
+	return snap
+}
+
+// parseProcStat parses the CPU, interrupt, context switch and process lines of /proc/stat.
+func parseProcStat(content string) *procStat {
+	st := &procStat{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(fields[0], "cpu"):
+			name := fields[0]
+			if name == "cpu" {
+				name = "all"
+			}
+			values := make([]float64, 10)
+			for i := 1; i < len(fields) && i <= len(values); i++ {
+				values[i-1], _ = strconv.ParseFloat(fields[i], 64)
+			}
+			st.cpuNames = append(st.cpuNames, name)
+			st.cpus = append(st.cpus, values)
+		case fields[0] == "intr":
+			st.intr, _ = strconv.ParseFloat(fields[1], 64)
+		case fields[0] == "ctxt":
+			st.ctxt, _ = strconv.ParseFloat(fields[1], 64)
+		case fields[0] == "procs_running":
+			st.procsRunning, _ = strconv.ParseFloat(fields[1], 64)
+		case fields[0] == "procs_blocked":
+			st.procsBlocked, _ = strconv.ParseFloat(fields[1], 64)
+		}
+	}
+	return st
+}
+
+// parseNameValues parses "name value [unit]" lines such as /proc/vmstat and /proc/meminfo.
+func parseNameValues(content string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(fields[1], 64); err == nil {
+			values[strings.TrimSuffix(fields[0], ":")] = v
+		}
+	}
+	return values
+}
+
+// netDevColumns names the /proc/net/dev fields after the interface name
+var netDevColumns = []string{
+	"interface",
+	"rx_bytes", "rx_packets", "rx_errs", "rx_drop", "rx_fifo", "rx_frame", "rx_compressed", "rx_multicast",
+	"tx_bytes", "tx_packets", "tx_errs", "tx_drop", "tx_fifo", "tx_colls", "tx_carrier", "tx_compressed",
+}
+
+// parseNetDev converts /proc/net/dev into a table keyed on interface.
+func parseNetDev(content string) *tsvTable {
+	t := &tsvTable{Columns: netDevColumns}
+	for _, line := range strings.Split(content, "\n") {
+		name, counters, ok := strings.Cut(line, ":")
+		if !ok || strings.Contains(name, "|") {
+			continue
+		}
+		row := make([]string, len(netDevColumns))
+		row[0] = strings.TrimSpace(name)
+		copy(row[1:], strings.Fields(counters))
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// nfsOpColumns names the per-op fields of /proc/self/mountstats
+var nfsOpColumns = []string{
+	"mount", "op",
+	"ops", "transmissions", "major_timeouts", "bytes_sent", "bytes_recv",
+	"queue_ms", "rtt_ms", "execute_ms",
+}
+
+// parseNFSMountstats extracts the READ and WRITE per-op statistics of NFS
+// mounts from /proc/self/mountstats, keyed on mount point and op.
+func parseNFSMountstats(content string) *tsvTable {
+	t := &tsvTable{Columns: nfsOpColumns}
+	mount := ""
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "device" {
+			// device SERVER:/export mounted on /mnt with fstype nfs4 statvers=1.1
+			mount = ""
+			if len(fields) >= 8 && strings.HasPrefix(fields[7], "nfs") {
+				mount = fields[4]
+			}
+			continue
+		}
+		if mount == "" || (fields[0] != "READ:" && fields[0] != "WRITE:") || len(fields) < 9 {
+			continue
+		}
+		row := []string{mount, strings.TrimSuffix(fields[0], ":")}
+		row = append(row, fields[1:9]...)
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// tableValues indexes a table's numeric columns by row key.
+func tableValues(t *tsvTable, keyColumns int) (order []string, rows map[string]map[string]float64) {
+	rows = make(map[string]map[string]float64)
+	if t == nil {
+		return nil, rows
+	}
+	for _, row := range t.Rows {
+		key := strings.Join(row[:keyColumns], "\x00")
+		values := make(map[string]float64)
+		for i := keyColumns; i < len(row) && i < len(t.Columns); i++ {
+			if v, err := strconv.ParseFloat(row[i], 64); err == nil {
+				values[t.Columns[i]] = v
+			}
+		}
+		order = append(order, key)
+		rows[key] = values
+	}
+	return order, rows
+}
+
+// formatStat renders a sampler value with two decimals, like sysstat.
+func formatStat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// perSecond divides a counter difference by the interval, clamping resets to zero.
+func perSecond(after, before, seconds float64) float64 {
+	if after < before || seconds <= 0 {
+		return 0
+	}
+	return (after - before) / seconds
+}
+
+// ratio divides two counter differences, returning zero when nothing happened.
+func ratio(numerator, denominator float64) float64 {
+	if denominator <= 0 || numerator < 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// intervals calls fn for each pair of consecutive snapshots.
+func intervals(snaps []procSnapshot, fn func(ts string, before, after procSnapshot, seconds float64)) {
+	for i := 1; i < len(snaps); i++ {
+		fn(snaps[i].taken.Format(SampleTimestampFormat), snaps[i-1], snaps[i], snaps[i].taken.Sub(snaps[i-1].taken).Seconds())
+	}
+}
+
+// mpstatReport computes per-CPU utilization percentages like "mpstat -P ALL".
+func mpstatReport(snaps []procSnapshot) (*tsvTable, error) {
+	t := &tsvTable{Columns: []string{SampleTimestampColumn, "cpu", "usr", "nice", "sys", "iowait", "irq", "soft", "steal", "guest", "gnice", "idle"}}
+	intervals(snaps, func(ts string, before, after procSnapshot, _ float64) {
+		if before.stat == nil || after.stat == nil || len(before.stat.cpus) != len(after.stat.cpus) {
+			return
+		}
+		for i, name := range after.stat.cpuNames {
+			d := make([]float64, 10)
+			for j := range d {
+				d[j] = after.stat.cpus[i][j] - before.stat.cpus[i][j]
+			}
+			// user and nice already include guest and guest_nice time
+			total := d[0] + d[1] + d[2] + d[3] + d[4] + d[5] + d[6] + d[7]
+			pct := func(v float64) string { return formatStat(ratio(v, total) * 100) }
+			t.Rows = append(t.Rows, []string{
+				ts, name,
+				pct(d[0] - d[8]), pct(d[1] - d[9]), pct(d[2]), pct(d[4]), pct(d[5]), pct(d[6]),
+				pct(d[7]), pct(d[8]), pct(d[9]), pct(d[3]),
+			})
+		}
+	})
+	if len(t.Rows) == 0 {
+		return nil, NewSkipError("/proc/stat not available")
+	}
+	return t, nil
+}
+
+// iostatReport computes extended per-device statistics like "iostat -x".
+func iostatReport(snaps []procSnapshot) (*tsvTable, error) {
+	t := &tsvTable{Columns: []string{SampleTimestampColumn, "device",
+		"r_s", "rkb_s", "rrqm_s", "r_await", "w_s", "wkb_s", "wrqm_s", "w_await", "aqu_sz", "util_pct"}}
+	intervals(snaps, func(ts string, before, after procSnapshot, seconds float64) {
+		_, prev := tableValues(before.disks, 1)
+		order, cur := tableValues(after.disks, 1)
+		for _, dev := range order {
+			b, ok := prev[dev]
+			if !ok {
+				continue
+			}
+			a := cur[dev]
+			d := func(col string) float64 { return a[col] - b[col] }
+			ms := seconds * 1000
+			t.Rows = append(t.Rows, []string{
+				ts, dev,
+				formatStat(perSecond(a["reads_completed"], b["reads_completed"], seconds)),
+				formatStat(perSecond(a["sectors_read"], b["sectors_read"], seconds) * DiskSectorBytes / 1024),
+				formatStat(perSecond(a["reads_merged"], b["reads_merged"], seconds)),
+				formatStat(ratio(d("read_ms"), d("reads_completed"))),
+				formatStat(perSecond(a["writes_completed"], b["writes_completed"], seconds)),
+				formatStat(perSecond(a["sectors_written"], b["sectors_written"], seconds) * DiskSectorBytes / 1024),
+				formatStat(perSecond(a["writes_merged"], b["writes_merged"], seconds)),
+				formatStat(ratio(d("write_ms"), d("writes_completed"))),
+				formatStat(ratio(d("weighted_io_ms"), ms)),
+				formatStat(min(ratio(d("io_ms"), ms)*100, 100)),
+			})
+		}
+	})
+	if len(t.Rows) == 0 {
+		return nil, NewSkipError("/proc/diskstats not available")
+	}
+	return t, nil
+}
+
+// vmstatReport computes process, memory, swap, I/O and CPU statistics like "vmstat".
+func vmstatReport(snaps []procSnapshot) (*tsvTable, error) {
+	t := &tsvTable{Columns: []string{SampleTimestampColumn,
+		"r", "b", "swpd", "free", "buff", "cache", "si", "so", "bi", "bo", "in", "cs", "us", "sy", "id", "wa", "st"}}
+	pageKB := float64(os.Getpagesize()) / 1024
+	intervals(snaps, func(ts string, before, after procSnapshot, seconds float64) {
+		if before.stat == nil || after.stat == nil || len(before.stat.cpus) == 0 || len(after.stat.cpus) == 0 ||
+			after.vmstat == nil || before.vmstat == nil || after.meminfo == nil {
+			return
+		}
+		m := after.meminfo
+		rate := func(name string) float64 { return perSecond(after.vmstat[name], before.vmstat[name], seconds) }
+
+		d := make([]float64, 8)
+		total := 0.0
+		for j := range d {
+			d[j] = after.stat.cpus[0][j] - before.stat.cpus[0][j]
+			total += d[j]
+		}
+		pct := func(v float64) string { return strconv.FormatFloat(ratio(v, total)*100, 'f', 0, 64) }
+		whole := func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) }
+
+		t.Rows = append(t.Rows, []string{
+			ts,
+			whole(after.stat.procsRunning), whole(after.stat.procsBlocked),
+			whole(m["SwapTotal"] - m["SwapFree"]), whole(m["MemFree"]), whole(m["Buffers"]),
+			whole(m["Cached"] + m["SReclaimable"]),
+			whole(rate("pswpin") * pageKB), whole(rate("pswpout") * pageKB),
+			whole(rate("pgpgin")), whole(rate("pgpgout")),
+			whole(perSecond(after.stat.intr, before.stat.intr, seconds)),
+			whole(perSecond(after.stat.ctxt, before.stat.ctxt, seconds)),
+			pct(d[0] + d[1]), pct(d[2] + d[5] + d[6]), pct(d[3]), pct(d[4]), pct(d[7]),
+		})
+	})
+	if len(t.Rows) == 0 {
+		return nil, NewSkipError("/proc/stat, /proc/vmstat or /proc/meminfo not available")
+	}
+	return t, nil
+}
+
+// netdevReport computes per-interface network statistics like "sar -n DEV,EDEV".
+func netdevReport(snaps []procSnapshot) (*tsvTable, error) {
+	t := &tsvTable{Columns: []string{SampleTimestampColumn, "iface",
+		"rxpck_s", "txpck_s", "rxkb_s", "txkb_s", "rxerr_s", "txerr_s", "rxdrop_s", "txdrop_s"}}
+	intervals(snaps, func(ts string, before, after procSnapshot, seconds float64) {
+		_, prev := tableValues(before.netdev, 1)
+		order, cur := tableValues(after.netdev, 1)
+		for _, iface := range order {
+			b, ok := prev[iface]
+			if !ok {
+				continue
+			}
+			a := cur[iface]
+			rate := func(col string) string { return formatStat(perSecond(a[col], b[col], seconds)) }
+			t.Rows = append(t.Rows, []string{
+				ts, iface,
+				rate("rx_packets"), rate("tx_packets"),
+				formatStat(perSecond(a["rx_bytes"], b["rx_bytes"], seconds) / 1024),
+				formatStat(perSecond(a["tx_bytes"], b["tx_bytes"], seconds) / 1024),
+				rate("rx_errs"), rate("tx_errs"), rate("rx_drop"), rate("tx_drop"),
+			})
+		}
+	})
+	if len(t.Rows) == 0 {
+		return nil, NewSkipError("/proc/net/dev not available")
+	}
+	return t, nil
+}
+
+// nfsiostatReport computes per-mount READ and WRITE statistics like "nfsiostat".
+func nfsiostatReport(snaps []procSnapshot) (*tsvTable, error) {
+	t := &tsvTable{Columns: []string{SampleTimestampColumn, "mount", "op",
+		"ops_s", "kb_s", "kb_per_op", "retrans", "avg_rtt_ms", "avg_exe_ms"}}
+	intervals(snaps, func(ts string, before, after procSnapshot, seconds float64) {
+		_, prev := tableValues(before.nfs, 2)
+		order, cur := tableValues(after.nfs, 2)
+		for _, key := range order {
+			b, ok := prev[key]
+			if !ok {
+				continue
+			}
+			a := cur[key]
+			mount, op, _ := strings.Cut(key, "\x00")
+			d := func(col string) float64 { return a[col] - b[col] }
+			bytesCol := "bytes_recv"
+			if op == "WRITE" {
+				bytesCol = "bytes_sent"
+			}
+			t.Rows = append(t.Rows, []string{
+				ts, mount, op,
+				formatStat(perSecond(a["ops"], b["ops"], seconds)),
+				formatStat(perSecond(a[bytesCol], b[bytesCol], seconds) / 1024),
+				formatStat(ratio(d(bytesCol)/1024, d("ops"))),
+				strconv.FormatFloat(max(d("transmissions")-d("ops"), 0), 'f', 0, 64),
+				formatStat(ratio(d("rtt_ms"), d("ops"))),
+				formatStat(ratio(d("execute_ms"), d("ops"))),
+			})
+		}
+	})
+	if len(t.Rows) == 0 {
+		return nil, NewSkipError("no NFS mounts")
+	}
+	return t, nil
+}
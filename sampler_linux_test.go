@@ -0,0 +1,152 @@
+//go:build linux
+
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testSnapshots returns two snapshots one second apart
+func testSnapshots(before, after procSnapshot) []procSnapshot {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	before.taken = t0
+	after.taken = t0.Add(time.Second)
+	return []procSnapshot{before, after}
+}
+
+// TestMpstatReport verifies CPU percentages, with guest time excluded from usr
+func TestMpstatReport(t *testing.T) {
+	before := parseProcStat("cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 100 0 50 800 50 0 0 0 0 0\nctxt 10\n")
+	after := parseProcStat("cpu  160 0 70 900 70 0 0 0 20 0\ncpu0 160 0 70 900 70 0 0 0 20 0\nctxt 30\n")
+
+	table, err := mpstatReport(testSnapshots(procSnapshot{stat: before}, procSnapshot{stat: after}))
+	if err != nil {
+		t.Fatalf("mpstatReport failed: %v", err)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(table.Rows))
+	}
+	expected := []string{"all", "20.00", "0.00", "10.00", "10.00", "0.00", "0.00", "0.00", "10.00", "0.00", "50.00"}
+	if !reflect.DeepEqual(table.Rows[0][1:], expected) {
+		t.Errorf("unexpected row\nexpected: %q\ngot:      %q", expected, table.Rows[0][1:])
+	}
+}
+
+// TestIostatReport verifies throughput, await, queue size and utilization
+func TestIostatReport(t *testing.T) {
+	before := parseDiskstats("8 0 sda 100 0 2000 100 50 0 1000 200 0 300 500\n")
+	after := parseDiskstats("8 0 sda 200 10 4048 600 100 0 2024 700 1 800 1500\n")
+
+	table, err := iostatReport(testSnapshots(procSnapshot{disks: before}, procSnapshot{disks: after}))
+	if err != nil {
+		t.Fatalf("iostatReport failed: %v", err)
+	}
+	expected := []string{"sda", "100.00", "1024.00", "10.00", "5.00", "50.00", "512.00", "0.00", "10.00", "1.00", "50.00"}
+	if len(table.Rows) != 1 || !reflect.DeepEqual(table.Rows[0][1:], expected) {
+		t.Errorf("unexpected rows\nexpected: %q\ngot:      %q", expected, table.Rows)
+	}
+}
+
+// TestNetdevReport verifies per-interface packet and byte rates
+func TestNetdevReport(t *testing.T) {
+	header := "Inter-|   Receive |  Transmit\n face |bytes packets|bytes packets\n"
+	before := parseNetDev(header + "  eth0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n")
+	after := parseNetDev(header + "  eth0:3048 30 1 0 0 0 0 0 6096 60 0 2 0 0 0 0\n")
+
+	table, err := netdevReport(testSnapshots(procSnapshot{netdev: before}, procSnapshot{netdev: after}))
+	if err != nil {
+		t.Fatalf("netdevReport failed: %v", err)
+	}
+	expected := []string{"eth0", "20.00", "40.00", "2.00", "4.00", "1.00", "0.00", "0.00", "2.00"}
+	if len(table.Rows) != 1 || !reflect.DeepEqual(table.Rows[0][1:], expected) {
+		t.Errorf("unexpected rows\nexpected: %q\ngot:      %q", expected, table.Rows)
+	}
+}
+
+// TestNfsiostatReport verifies NFS mounts are found and non-NFS mounts ignored
+func TestNfsiostatReport(t *testing.T) {
+	mountstats := func(reads, bytes, rtt string) string {
+		return "device /dev/sda1 mounted on / with fstype ext4\n" +
+			"device srv:/export mounted on /mnt/nfs with fstype nfs4 statvers=1.1\n" +
+			"\tper-op statistics\n" +
+			"\t        NULL: 0 0 0 0 0 0 0 0 0\n" +
+			"\t        READ: " + reads + " " + reads + " 0 100 " + bytes + " 0 " + rtt + " " + rtt + " 0\n" +
+			"\t       WRITE: 0 0 0 0 0 0 0 0 0\n"
+	}
+	before := parseNFSMountstats(mountstats("10", "10240", "20"))
+	after := parseNFSMountstats(mountstats("20", "30720", "70"))
+	if len(before.Rows) != 2 || before.Rows[0][0] != "/mnt/nfs" {
+		t.Fatalf("unexpected mountstats rows %q", before.Rows)
+	}
+
+	table, err := nfsiostatReport(testSnapshots(procSnapshot{nfs: before}, procSnapshot{nfs: after}))
+	if err != nil {
+		t.Fatalf("nfsiostatReport failed: %v", err)
+	}
+	expected := []string{"/mnt/nfs", "READ", "10.00", "20.00", "2.00", "0", "5.00", "5.00"}
+	if len(table.Rows) != 2 || !reflect.DeepEqual(table.Rows[0][1:], expected) {
+		t.Errorf("unexpected rows\nexpected: %q\ngot:      %q", expected, table.Rows)
+	}
+
+	if _, err := nfsiostatReport(testSnapshots(procSnapshot{}, procSnapshot{})); err == nil {
+		t.Error("expected skip without NFS mounts")
+	}
+}
+
+// TestSamplerTaskCounts verifies the native reports cover as many intervals
+// as their commands, and that sar has no native text output
+func TestSamplerTaskCounts(t *testing.T) {
+	for _, task := range systemSamplerTasks {
+		if len(task.Args) == 0 {
+			continue
+		}
+		if n, err := strconv.Atoi(task.Args[len(task.Args)-1]); err == nil && n != task.Count {
+			t.Errorf("%s: command reports %d intervals, native report %d", task.Name, n, task.Count)
+		}
+	}
+
+	header := "Inter-|   Receive |  Transmit\n face |bytes packets|bytes packets\n"
+	sampler := &procSampler{}
+	sampler.once.Do(func() {})
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= 10; i++ {
+		line := fmt.Sprintf("  eth0: %d %d 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n", i*1000, i*10)
+		sampler.snapshots = append(sampler.snapshots, procSnapshot{taken: t0.Add(time.Duration(i) * time.Second), netdev: parseNetDev(header + line)})
+	}
+	table, err := SimpleSamplerTask{Count: 5, Report: netdevReport}.report(sampler)
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(table.Rows) != 5 {
+		t.Errorf("expected 5 intervals, got %d", len(table.Rows))
+	}
+
+	if _, err := exec.LookPath("sar"); err != nil {
+		for _, task := range systemSamplerTasks {
+			if task.Name != "sar" {
+				continue
+			}
+			var skipErr SkipError
+			if err := samplerTextCollector(task, sampler)(&Config{}, &bytes.Buffer{}); !errors.As(err, &skipErr) {
+				t.Errorf("expected sar.out to be skipped without sar, got %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"time"
+)
+
+// SampleTimestampColumn is the leading column added to sampled TSV output
+const SampleTimestampColumn = "sample_ts"
+
+// SampleTimestampFormat renders sample_ts like a PostgreSQL timestamptz
+const SampleTimestampFormat = "2006-01-02 15:04:05.000000-07"
+
+// volatileQueryTasks returns the instance-level query tasks that are sampled
+// repeatedly with --samples
+func volatileQueryTasks() []SimpleQueryTask {
+	var tasks []SimpleQueryTask
+	for _, t := range postgresQueryTasks {
+		if t.Volatile {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// withoutVolatileTasks drops the sampled collectors and their --raw-select
+// variants from a task list so they are not collected a second time as
+// one-time snapshots
+func withoutVolatileTasks(tasks []CollectionTask) []CollectionTask {
+	volatile := make(map[string]bool)
+	for _, t := range volatileQueryTasks() {
+		volatile[t.ArchivePath] = true
+		volatile[rawSelectPath(t.ArchivePath)] = true
+	}
+
+	var result []CollectionTask
+	for _, task := range tasks {
+		if !volatile[task.ArchivePath] {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// sampleBuffer accumulates all samples of one volatile collector
+type sampleBuffer struct {
+	data        bytes.Buffer
+	columnTypes []string
+	err         error // Last error, reported only if no sample succeeded
+}
+
+// sample is the output of one query run, with its column types
+type sample struct {
+	bytes.Buffer
+	columnTypes []string
+}
+
+// SetColumnTypes records the column types of the sampled query.
+func (s *sample) SetColumnTypes(types []string) {
+	s.columnTypes = types
+}
+
+// collectSamples runs the volatile collectors cfg.Samples times, cfg.Interval
+// apart, and writes a single TSV per collector with a sample_ts column. With
+// --raw-select, the unmodified SELECT * output is sampled as well.
+func collectSamples(cfg *Config, zipWriter *zip.Writer) int {
+	var tasks []SimpleQueryTask
+	for _, t := range volatileQueryTasks() {
+		if !runsOnRole(t.Role, cfg.Role) {
+			continue
+		}
+		tasks = append(tasks, t)
+		if cfg.RawSelect && t.Schema != nil {
+			raw := t
+			raw.Name += RawSelectSuffix
+			raw.ArchivePath = rawSelectPath(t.ArchivePath)
+			raw.Schema = nil
+			tasks = append(tasks, raw)
+		}
+	}
+	buffers := make([]sampleBuffer, len(tasks))
+
+	if cfg.Verbose {
+		infoLog.Printf("Sampling %d volatile collectors %d times every %s", len(tasks), cfg.Samples, cfg.Interval)
+	}
+
+	start := time.Now()
+	for i := 0; i < cfg.Samples; i++ {
+		// Keep samples on a fixed schedule regardless of query duration
+		if i > 0 {
+			time.Sleep(time.Until(start.Add(time.Duration(i) * cfg.Interval)))
+		}
+		ts := time.Now().Format(SampleTimestampFormat)
+
+		for j, t := range tasks {
+			var out sample
+			err := pgQueryToTSV(cfg.DB, expandQuery(t.Query, cfg), &out, tsvOptions{
+				Header:      buffers[j].data.Len() == 0,
+				PrefixName:  SampleTimestampColumn,
+				PrefixValue: ts,
+				Format:      cfg.QueryFormat,
+				Schema:      t.Schema,
+			})
+			if err != nil {
+				buffers[j].err = err
+				continue
+			}
+			if buffers[j].columnTypes == nil {
+				buffers[j].columnTypes = out.columnTypes
+			}
+			buffers[j].data.Write(out.Bytes())
+		}
+	}
+
+	// Emit the accumulated samples through the regular collection path
+	result := make([]CollectionTask, len(tasks))
+	for i, t := range tasks {
+		buf := &buffers[i]
+		result[i] = CollectionTask{
+			Category:    "postgresql",
+			Name:        t.Name,
+			ArchivePath: t.ArchivePath,
+			Collector: func(cfg *Config, w io.Writer) error {
+				if buf.data.Len() == 0 && buf.err != nil {
+					return buf.err
+				}
+				if tw, ok := w.(columnTypeWriter); ok && buf.columnTypes != nil {
+					tw.SetColumnTypes(buf.columnTypes)
+				}
+				_, err := w.Write(buf.data.Bytes())
+				return err
+			},
+			QueryResult: true,
+			Schema:      t.Schema,
+		}
+	}
+	return collect(cfg, zipWriter, result)
+}
@@ -0,0 +1,271 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestVolatileQueryTasks verifies the set of collectors sampled with --samples
+func TestVolatileQueryTasks(t *testing.T) {
+	expected := map[string]bool{
+		"activity":                   true,
+		"blocking_locks":             true,
+		"connection_summary":         true,
+		"replication":                true,
+		"replication_lag":            true,
+		"running_locks":              true,
+		"standby_lag":                true,
+		"stat_progress_analyze":      true,
+		"stat_progress_basebackup":   true,
+		"stat_progress_cluster":      true,
+		"stat_progress_copy":         true,
+		"stat_progress_create_index": true,
+		"stat_progress_vacuum":       true,
+		"waits_sample":               true,
+	}
+
+	tasks := volatileQueryTasks()
+	if len(tasks) != len(expected) {
+		t.Errorf("expected %d volatile tasks, got %d", len(expected), len(tasks))
+	}
+	for _, task := range tasks {
+		if !expected[task.Name] {
+			t.Errorf("unexpected volatile task %q", task.Name)
+		}
+	}
+}
+
+// TestWithoutVolatileTasks verifies sampled collectors are dropped from the one-time run
+func TestWithoutVolatileTasks(t *testing.T) {
+	all := getPostgreSQLTasks(nil)
+	remaining := withoutVolatileTasks(all)
+
+	removed := 0
+	for _, task := range volatileQueryTasks() {
+		removed++
+		if task.Schema != nil {
+			removed++ // The --raw-select variant
+		}
+	}
+	if len(remaining) != len(all)-removed {
+		t.Errorf("expected %d remaining tasks, got %d", len(all)-removed, len(remaining))
+	}
+	for _, task := range remaining {
+		if task.Name == "activity" || task.Name == "activity_raw" || task.Name == "waits_sample" {
+			t.Errorf("volatile task %q was not removed", task.Name)
+		}
+	}
+}
+
+// TestWriteRowsTSVPrefix verifies the leading sample column and header suppression
+func TestWriteRowsTSVPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     tsvOptions
+		expected string
+	}{
+		{"prefix with header", tsvOptions{Header: true, PrefixName: "sample_ts", PrefixValue: "T1"}, "sample_ts\tpid\nT1\t42\n"},
+		{"prefix without header", tsvOptions{PrefixName: "sample_ts", PrefixValue: "T2"}, "T2\t42\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock: %v", err)
+			}
+			defer closeErrCheck(db, "mock db")
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"pid"}).AddRow(42))
+
+			var buf bytes.Buffer
+			if err := pgQueryToTSV(db, "SELECT pid", &buf, tt.opts); err != nil {
+				t.Fatalf("pgQueryToTSV failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("output mismatch\nexpected: %q\ngot:      %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+// TestCollectSamples verifies samples are appended to a single TSV per collector
+func TestCollectSamples(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	const samples = 3
+	for i := 0; i < samples; i++ {
+		for _, task := range volatileQueryTasks() {
+			mock.ExpectQuery(regexp.QuoteMeta(task.Query)).
+				WillReturnRows(sqlmock.NewRows([]string{"pid"}).AddRow(i))
+		}
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	cfg := &Config{DB: db, Samples: samples, Interval: time.Millisecond, Role: RoleStandby}
+
+	collected := collectSamples(cfg, zipWriter)
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	if collected != len(volatileQueryTasks()) {
+		t.Errorf("expected %d collected, got %d", len(volatileQueryTasks()), collected)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading zip: %v", err)
+	}
+	for _, f := range reader.File {
+		if f.Name != "postgresql/running_activity.tsv" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		closeErrCheck(rc, "zip entry")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != samples+1 {
+			t.Fatalf("expected header and %d samples, got %d lines: %q", samples, len(lines), data)
+		}
+		// Sampled with the stable activity schema: pid is its third column
+		if !strings.HasPrefix(lines[0], "sample_ts\tdatid\tdatname\tpid\t") {
+			t.Errorf("unexpected header %q", lines[0])
+		}
+		for i, line := range lines[1:] {
+			if fields := strings.Split(line, "\t"); len(fields) < 4 || fields[3] != strconv.Itoa(i) {
+				t.Errorf("sample %d has unexpected row %q", i, line)
+			}
+		}
+		return
+	}
+	t.Error("running_activity.tsv not found in archive")
+}
+
+// TestCollectSamplesRawSelect verifies --raw-select output of volatile
+// collectors is sampled too
+func TestCollectSamplesRawSelect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	expected := 0
+	for _, task := range volatileQueryTasks() {
+		if !runsOnRole(task.Role, RolePrimary) {
+			continue
+		}
+		copies := 1
+		if task.Schema != nil {
+			copies = 2
+		}
+		for i := 0; i < copies; i++ {
+			mock.ExpectQuery(regexp.QuoteMeta(task.Query)).
+				WillReturnRows(sqlmock.NewRows([]string{"pid"}).AddRow(7))
+			expected++
+		}
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	cfg := &Config{DB: db, Samples: 1, Interval: time.Millisecond, Role: RolePrimary, RawSelect: true}
+	if collected := collectSamples(cfg, zipWriter); collected != expected {
+		t.Errorf("expected %d collected, got %d", expected, collected)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading zip: %v", err)
+	}
+	for _, f := range reader.File {
+		if f.Name != "postgresql/running_activity_raw.tsv" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		closeErrCheck(rc, "zip entry")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), "sample_ts\tpid\n") || !strings.HasSuffix(string(data), "\t7\n") {
+			t.Errorf("unexpected raw samples %q", data)
+		}
+		return
+	}
+	t.Error("running_activity_raw.tsv not found in archive")
+}
+
+// TestSamplingFlagValidation verifies --samples and --interval validation
+func TestSamplingFlagValidation(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+	}{
+		{"samples with interval", []string{"radar", "--samples", "5", "--interval", "2s"}, false},
+		{"negative samples rejected", []string{"radar", "--samples", "-1"}, true},
+		{"zero interval rejected", []string{"radar", "--samples", "5", "--interval", "0s"}, true},
+		{"samples with skip-postgres rejected", []string{"radar", "--samples", "5", "--skip-postgres"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag.CommandLine = flag.NewFlagSet("radar", flag.ContinueOnError)
+			os.Args = tt.args
+
+			_, err := parseConfig()
+			if tt.expectError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// RawSelectSuffix names the unmodified SELECT * output written next to a
+// collector with a stable schema when --raw-select is given
+const RawSelectSuffix = "_raw"
+
+// OutputSchema is the stable column list of a collector that reads a
+// catalog view with SELECT *. Columns the server does not have are written
+// as NULL and server columns not in the schema are dropped, so the output
+// keeps the same header on every PostgreSQL version. Version is increased
+// whenever the column list changes and is recorded in manifest.tsv.
+type OutputSchema struct {
+	Version int
+	Columns []SchemaColumn
+}
+
+// SchemaColumn is one column of an OutputSchema
+type SchemaColumn struct {
+	Name    string
+	Sources []string // Older server names of the column, tried after Name
+}
+
+// schemaColumns builds schema columns that keep their server names.
+func schemaColumns(names ...string) []SchemaColumn {
+	result := make([]SchemaColumn, len(names))
+	for i, name := range names {
+		result[i] = SchemaColumn{Name: name}
+	}
+	return result
+}
+
+// renamed builds a schema column read from older server names when Name is
+// absent.
+func renamed(name string, sources ...string) SchemaColumn {
+	return SchemaColumn{Name: name, Sources: sources}
+}
+
+// rawSelectPath returns the archive path of the unmodified output of a
+// collector with a stable schema.
+func rawSelectPath(path string) string {
+	return strings.TrimSuffix(path, ".tsv") + RawSelectSuffix + ".tsv"
+}
+
+// withoutRawTasks drops the unmodified SELECT * collectors, which only run
+// with --raw-select
+func withoutRawTasks(tasks []CollectionTask) []CollectionTask {
+	var result []CollectionTask
+	for _, task := range tasks {
+		if !task.Raw {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// project maps a result set onto the schema.
+func (s *OutputSchema) project(rows textRows) textRows {
+	server := make(map[string]int)
+	for i, name := range rows.Columns() {
+		if _, ok := server[name]; !ok {
+			server[name] = i
+		}
+	}
+	serverTypes := rows.ColumnTypes()
+
+	p := &schemaRows{textRows: rows, schema: s, index: make([]int, len(s.Columns)), types: make([]string, len(s.Columns))}
+	for i, col := range s.Columns {
+		p.index[i] = -1
+		for _, name := range append([]string{col.Name}, col.Sources...) {
+			if j, ok := server[name]; ok {
+				p.index[i] = j
+				if j < len(serverTypes) {
+					p.types[i] = serverTypes[j]
+				}
+				break
+			}
+		}
+	}
+	return p
+}
+
+// schemaRows is a result set projected onto an OutputSchema
+type schemaRows struct {
+	textRows
+	schema *OutputSchema
+	index  []int // Server column of each schema column, -1 if absent
+	types  []string
+}
+
+// Columns returns the schema's column names.
+func (r *schemaRows) Columns() []string {
+	names := make([]string, len(r.schema.Columns))
+	for i, col := range r.schema.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// ColumnTypes returns the server types of the schema columns, "" for absent ones.
+func (r *schemaRows) ColumnTypes() []string {
+	return r.types
+}
+
+// Values returns the current row in schema column order.
+func (r *schemaRows) Values() ([]sql.NullString, error) {
+	values, err := r.textRows.Values()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]sql.NullString, len(r.index))
+	for i, j := range r.index {
+		if j >= 0 && j < len(values) {
+			result[i] = values[j]
+		}
+	}
+	return result, nil
+}
+
+// Stable schemas of the SELECT * collectors, covering the columns of
+// PostgreSQL 12 to 18 plus the pre-10 names of renamed columns.
+
+var activitySchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"datid", "datname", "pid", "leader_pid", "usesysid", "usename",
+	"application_name", "client_addr", "client_hostname", "client_port",
+	"backend_start", "xact_start", "query_start", "state_change",
+	"wait_event_type", "wait_event", "state", "backend_xid", "backend_xmin",
+	"query_id", "query", "backend_type",
+)}
+
+var bgwriterSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"checkpoints_timed", "checkpoints_req", "checkpoint_write_time",
+	"checkpoint_sync_time", "buffers_checkpoint", "buffers_clean",
+	"maxwritten_clean", "buffers_backend", "buffers_backend_fsync",
+	"buffers_alloc", "stats_reset",
+)}
+
+var checkpointerSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"num_timed", "num_requested", "num_done", "restartpoints_timed",
+	"restartpoints_req", "restartpoints_done", "write_time", "sync_time",
+	"buffers_written", "slru_written", "stats_reset",
+)}
+
+var databaseConflictsSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"datid", "datname", "confl_tablespace", "confl_lock", "confl_snapshot",
+	"confl_bufferpin", "confl_deadlock", "confl_active_logicalslot",
+)}
+
+var hbaFileRulesSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"rule_number", "file_name", "line_number", "type", "database",
+	"user_name", "address", "netmask", "auth_method", "options", "error",
+)}
+
+var replicationSchema = &OutputSchema{Version: 1, Columns: []SchemaColumn{
+	{Name: "pid"}, {Name: "usesysid"}, {Name: "usename"},
+	{Name: "application_name"}, {Name: "client_addr"},
+	{Name: "client_hostname"}, {Name: "client_port"},
+	{Name: "backend_start"}, {Name: "backend_xmin"}, {Name: "state"},
+	renamed("sent_lsn", "sent_location"),
+	renamed("write_lsn", "write_location"),
+	renamed("flush_lsn", "flush_location"),
+	renamed("replay_lsn", "replay_location"),
+	{Name: "write_lag"}, {Name: "flush_lag"}, {Name: "replay_lag"},
+	{Name: "sync_priority"}, {Name: "sync_state"}, {Name: "reply_time"},
+}}
+
+var replicationSlotsSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"slot_name", "plugin", "slot_type", "datoid", "database", "temporary",
+	"active", "active_pid", "xmin", "catalog_xmin", "restart_lsn",
+	"confirmed_flush_lsn", "wal_status", "safe_wal_size", "two_phase",
+	"two_phase_at", "inactive_since", "conflicting", "invalidation_reason",
+	"failover", "synced",
+)}
+
+var rolesSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"rolname", "rolsuper", "rolinherit", "rolcreaterole", "rolcreatedb",
+	"rolcanlogin", "rolreplication", "rolconnlimit", "rolpassword",
+	"rolvaliduntil", "rolbypassrls", "rolconfig", "oid",
+)}
+
+var runningLocksSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"locktype", "database", "relation", "page", "tuple", "virtualxid",
+	"transactionid", "classid", "objid", "objsubid", "virtualtransaction",
+	"pid", "mode", "granted", "fastpath", "waitstart",
+)}
+
+var statIOSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"backend_type", "object", "context", "reads", "read_bytes", "read_time",
+	"writes", "write_bytes", "write_time", "writebacks", "writeback_time",
+	"extends", "extend_bytes", "extend_time", "op_bytes", "hits",
+	"evictions", "reuses", "fsyncs", "fsync_time", "stats_reset",
+)}
+
+var statProgressVacuumSchema = &OutputSchema{Version: 1, Columns: []SchemaColumn{
+	{Name: "pid"}, {Name: "datid"}, {Name: "datname"}, {Name: "relid"},
+	{Name: "phase"}, {Name: "heap_blks_total"}, {Name: "heap_blks_scanned"},
+	{Name: "heap_blks_vacuumed"}, {Name: "index_vacuum_count"},
+	{Name: "max_dead_tuple_bytes"}, {Name: "max_dead_tuples"},
+	{Name: "dead_tuple_bytes"},
+	renamed("num_dead_item_ids", "num_dead_tuples"),
+	{Name: "indexes_total"}, {Name: "indexes_processed"},
+	{Name: "delay_time"},
+}}
+
+var statSLRUSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"name", "blks_zeroed", "blks_hit", "blks_read", "blks_written",
+	"blks_exists", "flushes", "truncates", "stats_reset",
+)}
+
+var statWALSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"wal_records", "wal_fpi", "wal_bytes", "wal_buffers_full", "wal_write",
+	"wal_sync", "wal_write_time", "wal_sync_time", "stats_reset",
+)}
+
+var subscriptionsSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"oid", "subdbid", "subskiplsn", "subname", "subowner", "subenabled",
+	"subbinary", "substream", "subtwophasestate", "subdisableonerr",
+	"subpasswordrequired", "subrunasowner", "subfailover", "subconninfo",
+	"subslotname", "subsynccommit", "subpublications", "suborigin",
+)}
+
+var walReceiverSchema = &OutputSchema{Version: 1, Columns: []SchemaColumn{
+	{Name: "pid"}, {Name: "status"}, {Name: "receive_start_lsn"},
+	{Name: "receive_start_tli"},
+	renamed("written_lsn", "received_lsn"),
+	{Name: "flushed_lsn"}, {Name: "received_tli"},
+	{Name: "last_msg_send_time"}, {Name: "last_msg_receipt_time"},
+	{Name: "latest_end_lsn"}, {Name: "latest_end_time"}, {Name: "slot_name"},
+	{Name: "sender_host"}, {Name: "sender_port"}, {Name: "conninfo"},
+}}
+
+var extensionsSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"oid", "extname", "extowner", "extnamespace", "extrelocatable",
+	"extversion", "extconfig", "extcondition",
+)}
+
+var triggersSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"oid", "tgrelid", "tgparentid", "tgname", "tgfoid", "tgtype",
+	"tgenabled", "tgisinternal", "tgconstrrelid", "tgconstrindid",
+	"tgconstraint", "tgdeferrable", "tginitdeferred", "tgnargs", "tgattr",
+	"tgargs", "tgqual", "tgoldtable", "tgnewtable",
+)}
+
+// pg_statviz snapshot tables
+
+var statvizBufSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "checkpoints_timed", "checkpoints_req",
+	"checkpoint_write_time", "checkpoint_sync_time", "buffers_checkpoint",
+	"buffers_clean", "maxwritten_clean", "buffers_backend",
+	"buffers_backend_fsync", "buffers_alloc", "stats_reset",
+)}
+
+var statvizConfSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "conf",
+)}
+
+var statvizConnSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "conn_total", "conn_active", "conn_idle",
+	"conn_idle_trans", "conn_idle_trans_abort", "conn_fastpath", "conn_users",
+)}
+
+var statvizIOSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "io_stats", "stats_reset",
+)}
+
+var statvizLockSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "locks_total", "locks",
+)}
+
+var statvizReplSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "standby_lag", "slot_stats",
+)}
+
+var statvizSLRUSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "slru_stats",
+)}
+
+var statvizSnapshotsSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp",
+)}
+
+var statvizWaitSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "wait_events_total", "wait_events",
+)}
+
+var statvizWALSchema = &OutputSchema{Version: 1, Columns: schemaColumns(
+	"snapshot_tstamp", "wal_records", "wal_fpi", "wal_bytes",
+	"wal_buffers_full", "wal_write", "wal_sync", "wal_write_time",
+	"wal_sync_time", "stats_reset",
+)}
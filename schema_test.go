@@ -0,0 +1,114 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestOutputSchemaProject verifies renamed, missing and extra server columns map onto the stable schema
+func TestOutputSchemaProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("future_column").OfType("TEXT", ""),
+		sqlmock.NewColumn("received_lsn").OfType("PG_LSN", ""),
+		sqlmock.NewColumn("pid").OfType("INT4", int64(0)),
+	).AddRow("x", "0/3000060", int64(42)))
+
+	schema := &OutputSchema{Version: 2, Columns: []SchemaColumn{
+		{Name: "pid"}, renamed("written_lsn", "received_lsn"), {Name: "flushed_lsn"},
+	}}
+	var out sample
+	if err := pgQueryToTSV(db, "SELECT", &out, tsvOptions{Header: true, Schema: schema}); err != nil {
+		t.Fatalf("pgQueryToTSV failed: %v", err)
+	}
+	expected := "pid\twritten_lsn\tflushed_lsn\n42\t0/3000060\t\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	types := []string{"INT4", "PG_LSN", ""}
+	for i := range types {
+		if i >= len(out.columnTypes) || out.columnTypes[i] != types[i] {
+			t.Fatalf("expected types %q, got %q", types, out.columnTypes)
+		}
+	}
+}
+
+// TestRawSelectTasks verifies stable schema collectors get a --raw-select variant and a manifest version
+func TestRawSelectTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	tasks := buildQueryTasks("postgresql", []SimpleQueryTask{
+		{Name: "roles", ArchivePath: "postgresql/roles.tsv", Query: "SELECT * FROM pg_roles", Schema: rolesSchema},
+		{Name: "version", ArchivePath: "postgresql/version.tsv", Query: "SELECT version()"},
+	}, db)
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(tasks))
+	}
+	raw := tasks[1]
+	if !raw.Raw || raw.Name != "roles_raw" || raw.ArchivePath != "postgresql/roles_raw.tsv" || raw.Schema != nil {
+		t.Errorf("unexpected raw task %+v", raw)
+	}
+	if len(withoutRawTasks(tasks)) != 2 {
+		t.Errorf("expected withoutRawTasks to drop the raw task")
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"rolname", "rolcatupdate"}).AddRow("postgres", true))
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	cfg := &Config{}
+	if collect(cfg, zipWriter, tasks[:1]) != 1 {
+		t.Fatalf("expected roles to be collected: %+v", cfg.Results)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Results[0].SchemaVersion != rolesSchema.Version {
+		t.Errorf("expected schema version %d, got %d", rolesSchema.Version, cfg.Results[0].SchemaVersion)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := reader.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rc)
+	closeErrCheck(rc, "zip entry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := parseTSV(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Columns) != len(rolesSchema.Columns) || table.columnIndex("rolcatupdate") != -1 {
+		t.Errorf("unexpected columns %q", table.Columns)
+	}
+	if table.Rows[0][table.columnIndex("rolname")] != "postgres" || table.Rows[0][table.columnIndex("rolsuper")] != "" {
+		t.Errorf("unexpected row %q", table.Rows[0])
+	}
+}
@@ -0,0 +1,403 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Serve Defaults
+const (
+	DefaultListen   = ":9187"
+	DefaultCacheTTL = 10 * time.Second
+	MetricsPrefix   = "radar_"
+	MetricsPath     = "/metrics"
+)
+
+// MetricsContentType is the Prometheus text exposition format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// SimpleGaugeTask defines a source of current values for radar serve
+type SimpleGaugeTask struct {
+	Name     string   // Source name, used in metric names
+	Keys     []string // Identifying columns, exported as labels
+	Counters []string // Cumulative columns; all other numeric columns are gauges
+	Snapshot func(*Config) (*tsvTable, error)
+}
+
+// pgGaugeTask builds a gauge source from a query.
+func pgGaugeTask(name string, keys []string, query string) SimpleGaugeTask {
+	return SimpleGaugeTask{
+		Name: name,
+		Keys: keys,
+		Snapshot: func(cfg *Config) (*tsvTable, error) {
+			var buf bytes.Buffer
+			if err := pgQueryToTSV(cfg.DB, query, &buf, tsvOptions{Header: true}); err != nil {
+				return nil, err
+			}
+			return parseTSV(buf.Bytes())
+		},
+	}
+}
+
+// PostgreSQL current values exported by radar serve (sorted alphabetically by name).
+// The counter sources of --delta are exported as well.
+var postgresGaugeTasks = []SimpleGaugeTask{
+	pgGaugeTask("databases", []string{"datname"}, `
+		SELECT datname, numbackends, pg_database_size(datid) AS size_bytes
+		FROM pg_stat_database
+		WHERE datname IS NOT NULL
+		ORDER BY datname
+	`),
+	pgGaugeTask("recovery", nil, `
+		SELECT pg_is_in_recovery()::int AS in_recovery,
+		    coalesce(CASE WHEN pg_is_in_recovery()
+		        THEN pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())
+		    END, 0) AS replay_lag_bytes,
+		    coalesce(CASE WHEN pg_is_in_recovery()
+		        THEN extract(epoch FROM now() - pg_last_xact_replay_timestamp())
+		    END, 0) AS replay_delay_seconds
+	`),
+	pgGaugeTask("replication", []string{"application_name", "client_addr", "pid"}, `
+		SELECT application_name, coalesce(client_addr::text, '') AS client_addr, pid,
+		    pg_wal_lsn_diff(w.lsn, sent_lsn) AS sent_lag_bytes,
+		    pg_wal_lsn_diff(w.lsn, flush_lsn) AS flush_lag_bytes,
+		    pg_wal_lsn_diff(w.lsn, replay_lsn) AS replay_lag_bytes,
+		    extract(epoch FROM write_lag) AS write_lag_seconds,
+		    extract(epoch FROM flush_lag) AS flush_lag_seconds,
+		    extract(epoch FROM replay_lag) AS replay_lag_seconds
+		FROM pg_stat_replication,
+		    (SELECT CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END AS lsn) w
+		ORDER BY application_name, client_addr, pid
+	`),
+}
+
+// metricSource is one snapshot source rendered on /metrics
+type metricSource struct {
+	name     string
+	keys     []string
+	postgres bool
+	counter  func(column string) bool
+	snapshot func(*Config) (*tsvTable, error)
+}
+
+// getMetricSources returns the counter and gauge sources applicable to this run.
+func getMetricSources(cfg *Config) []metricSource {
+	var sources []metricSource
+	addCounters := func(tasks []SimpleCounterTask, postgres bool) {
+		for _, t := range tasks {
+			gauges := t.Gauges
+			sources = append(sources, metricSource{
+				name:     t.Name,
+				keys:     t.Keys,
+				postgres: postgres,
+				counter:  func(column string) bool { return !containsString(gauges, column) },
+				snapshot: t.Snapshot,
+			})
+		}
+	}
+	addGauges := func(tasks []SimpleGaugeTask, postgres bool) {
+		for _, t := range tasks {
+			counters := t.Counters
+			sources = append(sources, metricSource{
+				name:     t.Name,
+				keys:     t.Keys,
+				postgres: postgres,
+				counter:  func(column string) bool { return containsString(counters, column) },
+				snapshot: t.Snapshot,
+			})
+		}
+	}
+
+	if !cfg.SkipSystem {
+		addCounters(systemCounterTasks, false)
+		addGauges(systemGaugeTasks, false)
+	}
+	if !cfg.SkipPostgres {
+		addCounters(postgresCounterTasks, true)
+		addGauges(postgresGaugeTasks, true)
+	}
+	return sources
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// metricFamily is a metric name with its type and samples
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string // counter or gauge
+	samples []string
+}
+
+// metricSet collects metric families in order of first appearance
+type metricSet struct {
+	families []*metricFamily
+	byName   map[string]*metricFamily
+}
+
+// add appends a sample to its family, creating the family if needed.
+func (m *metricSet) add(name, help, kind string, labels [][2]string, value float64) {
+	f, ok := m.byName[name]
+	if !ok {
+		f = &metricFamily{name: name, help: help, kind: kind}
+		if m.byName == nil {
+			m.byName = make(map[string]*metricFamily)
+		}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+
+	sample := name
+	if len(labels) > 0 {
+		parts := make([]string, len(labels))
+		for i, l := range labels {
+			parts[i] = l[0] + `="` + escapeLabelValue(l[1]) + `"`
+		}
+		sample += "{" + strings.Join(parts, ",") + "}"
+	}
+	f.samples = append(f.samples, sample+" "+strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// addTable adds every numeric column of a snapshot, labelled by its key columns.
+func (m *metricSet) addTable(src metricSource, t *tsvTable) {
+	keyCols := make([]int, len(src.keys))
+	for i, k := range src.keys {
+		keyCols[i] = t.columnIndex(k)
+	}
+
+	for _, row := range t.Rows {
+		labels := make([][2]string, 0, len(src.keys))
+		for i, k := range src.keys {
+			if keyCols[i] >= 0 {
+				labels = append(labels, [2]string{sanitizeMetricName(k), row[keyCols[i]]})
+			}
+		}
+		for i, col := range t.Columns {
+			if containsString(src.keys, col) {
+				continue
+			}
+			v, err := strconv.ParseFloat(row[i], 64)
+			if err != nil {
+				// NULL, text and timestamp columns
+				continue
+			}
+			name := MetricsPrefix + sanitizeMetricName(src.name) + "_" + sanitizeMetricName(col)
+			kind := "gauge"
+			if src.counter(col) {
+				kind = "counter"
+				if !strings.HasSuffix(name, "_total") {
+					name += "_total"
+				}
+			}
+			m.add(name, col+" from "+src.name, kind, labels, v)
+		}
+	}
+}
+
+// write renders the metric families in Prometheus text exposition format.
+func (m *metricSet) write(w io.Writer) error {
+	for _, f := range m.families {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind); err != nil {
+			return err
+		}
+		for _, s := range f.samples {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sanitizeMetricName replaces characters not allowed in metric and label names.
+func sanitizeMetricName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// escapeLabelValue escapes a label value for the text exposition format.
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// gatherMetrics runs every source and returns the metrics, including the
+// duration and success of each source.
+func gatherMetrics(cfg *Config, sources []metricSource) *metricSet {
+	m := &metricSet{}
+	type outcome struct {
+		name     string
+		duration time.Duration
+		ok       bool
+	}
+	var outcomes []outcome
+
+	for _, src := range sources {
+		start := time.Now()
+		var table *tsvTable
+		var err error
+		if src.postgres && cfg.DB == nil {
+			err = errors.New("not connected to PostgreSQL")
+		} else {
+			table, err = src.snapshot(cfg)
+		}
+		if err == nil {
+			m.addTable(src, table)
+		} else if cfg.VeryVerbose {
+			infoLog.Printf("⊘ metrics/%s: %v", src.name, err)
+		}
+		outcomes = append(outcomes, outcome{src.name, time.Since(start), err == nil})
+	}
+
+	for _, o := range outcomes {
+		labels := [][2]string{{"collector", o.name}}
+		m.add(MetricsPrefix+"collector_duration_seconds", "time taken by each collector", "gauge", labels, o.duration.Seconds())
+	}
+	for _, o := range outcomes {
+		success := 0.0
+		if o.ok {
+			success = 1
+		}
+		labels := [][2]string{{"collector", o.name}}
+		m.add(MetricsPrefix+"collector_success", "whether each collector succeeded", "gauge", labels, success)
+	}
+	return m
+}
+
+// exporter serves /metrics, caching each scrape for the cache TTL
+type exporter struct {
+	cfg *Config
+	ttl time.Duration
+
+	mu      sync.Mutex // Serializes scrapes, so concurrent scrapers share one
+	body    []byte
+	scraped time.Time
+}
+
+// metrics returns the rendered metrics, gathering them if the cache has expired.
+func (e *exporter) metrics() []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.scraped.IsZero() && time.Since(e.scraped) < e.ttl {
+		return e.body
+	}
+
+	connectPersistent(e.cfg)
+	var buf bytes.Buffer
+	if err := gatherMetrics(e.cfg, getMetricSources(e.cfg)).write(&buf); err != nil {
+		errorLog.Printf("Rendering metrics: %v", err)
+	}
+	e.body, e.scraped = buf.Bytes(), time.Now()
+	return e.body
+}
+
+// ServeHTTP implements http.Handler for the metrics endpoint.
+func (e *exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", MetricsContentType)
+	if _, err := w.Write(e.metrics()); err != nil && e.cfg.Verbose {
+		infoLog.Printf("Writing metrics: %v", err)
+	}
+}
+
+// runServe is the entry point for "radar serve". It exposes the numeric
+// collectors as Prometheus metrics until SIGINT or SIGTERM.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("radar serve", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: radar serve [options]\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	cfg := &Config{}
+	registerConfigFlags(fs, cfg)
+	listen := fs.String("listen", DefaultListen, "address to serve metrics on")
+	cacheTTL := fs.Duration("cache-ttl", DefaultCacheTTL, "reuse collected metrics for scrapes within this interval")
+
+	err := fs.Parse(args)
+	if err == nil && fs.NArg() > 0 {
+		err = fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if err == nil {
+		cfg, err = finishConfig(fs, cfg)
+	}
+	if err == nil && *cacheTTL < 0 {
+		err = fmt.Errorf("--cache-ttl must not be negative")
+	}
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			errorLog.Println(err)
+		}
+		return ExitUsageError
+	}
+
+	e := &exporter{cfg: cfg, ttl: *cacheTTL}
+	defer func() {
+		if cfg.DB != nil {
+			closeErrCheck(cfg.DB, "database connection")
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "radar exporter\n\nMetrics are served at %s\n", MetricsPath)
+	})
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-stop
+		infoLog.Printf("Received %s, shutting down", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			errorLog.Printf("Shutting down: %v", err)
+		}
+	}()
+
+	infoLog.Printf("radar serve listening on %s%s", *listen, MetricsPath)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errorLog.Println(err)
+		return ExitCollectError
+	}
+	return 0
+}
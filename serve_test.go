@@ -0,0 +1,154 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestGatherMetrics verifies labels, metric types and per-collector outcomes
+func TestGatherMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT").WillReturnRows(
+		sqlmock.NewRows([]string{"datname", "numbackends", "size_bytes"}).
+			AddRow("app", 3, 8192).
+			AddRow(`we"ird`, 1, nil))
+
+	cfg := &Config{DB: db}
+	sources := []metricSource{
+		{
+			name:     "databases",
+			keys:     []string{"datname"},
+			postgres: true,
+			counter:  func(string) bool { return false },
+			snapshot: postgresGaugeTasks[0].Snapshot,
+		},
+		{
+			name:    "stat_wal",
+			counter: func(string) bool { return true },
+			snapshot: func(*Config) (*tsvTable, error) {
+				return &tsvTable{Columns: []string{"wal_records", "stats_reset"}, Rows: [][]string{{"42", "2026-03-01 12:00:00+00"}}}, nil
+			},
+		},
+		{
+			name:     "broken",
+			counter:  func(string) bool { return false },
+			snapshot: func(*Config) (*tsvTable, error) { return nil, errors.New("boom") },
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := gatherMetrics(cfg, sources).write(&buf); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE radar_databases_numbackends gauge\n",
+		`radar_databases_numbackends{datname="app"} 3` + "\n",
+		`radar_databases_numbackends{datname="we\"ird"} 1` + "\n",
+		`radar_databases_size_bytes{datname="app"} 8192` + "\n",
+		"# TYPE radar_stat_wal_wal_records_total counter\nradar_stat_wal_wal_records_total 42\n",
+		`radar_collector_success{collector="databases"} 1` + "\n",
+		`radar_collector_success{collector="broken"} 0` + "\n",
+		`radar_collector_duration_seconds{collector="stat_wal"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "stats_reset") || strings.Contains(out, `size_bytes{datname="we`) {
+		t.Errorf("non-numeric values should be skipped:\n%s", out)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestReplicationMetricsPerWalsender verifies standbys sharing an application
+// name and address are exported as separate series
+func TestReplicationMetricsPerWalsender(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("FROM pg_stat_replication").WillReturnRows(
+		sqlmock.NewRows([]string{"application_name", "client_addr", "pid", "replay_lag_bytes"}).
+			AddRow("walreceiver", "", 101, 0).
+			AddRow("walreceiver", "", 102, 4096))
+
+	var source metricSource
+	for _, task := range postgresGaugeTasks {
+		if task.Name == "replication" {
+			source = metricSource{name: task.Name, keys: task.Keys, postgres: true,
+				counter: func(string) bool { return false }, snapshot: task.Snapshot}
+		}
+	}
+	var buf bytes.Buffer
+	if err := gatherMetrics(&Config{DB: db}, []metricSource{source}).write(&buf); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, want := range []string{
+		`radar_replication_replay_lag_bytes{application_name="walreceiver",client_addr="",pid="101"} 0` + "\n",
+		`radar_replication_replay_lag_bytes{application_name="walreceiver",client_addr="",pid="102"} 4096` + "\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+// TestGatherMetricsDisconnected verifies PostgreSQL sources fail without a connection
+func TestGatherMetricsDisconnected(t *testing.T) {
+	sources := []metricSource{{name: "bgwriter", postgres: true, counter: func(string) bool { return true }}}
+	var buf bytes.Buffer
+	if err := gatherMetrics(&Config{}, sources).write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `radar_collector_success{collector="bgwriter"} 0`) {
+		t.Errorf("expected failed collector, got:\n%s", buf.String())
+	}
+}
+
+// TestExporterCache verifies scrapes within the cache TTL reuse the last result
+func TestExporterCache(t *testing.T) {
+	e := &exporter{cfg: &Config{SkipSystem: true, SkipPostgres: true}, ttl: time.Hour}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", MetricsPath, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != MetricsContentType {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	first := e.scraped
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", MetricsPath, nil))
+	if !e.scraped.Equal(first) {
+		t.Error("expected cached metrics within the TTL")
+	}
+
+	e.ttl = 0
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", MetricsPath, nil))
+	if e.scraped.Equal(first) {
+		t.Error("expected a new scrape after the TTL")
+	}
+}
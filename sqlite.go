@@ -0,0 +1,261 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, keeps CGO_ENABLED=0 builds working
+)
+
+// Output formats of the main collection
+const (
+	FormatZIP    = "zip"
+	FormatSQLite = "sqlite"
+)
+
+// SQLiteFilesTable holds the command and file outputs of a SQLite bundle
+const SQLiteFilesTable = "files"
+
+// SQLite column types (type affinities)
+const (
+	SQLiteInteger = "INTEGER"
+	SQLiteReal    = "REAL"
+	SQLiteNumeric = "NUMERIC"
+	SQLiteBoolean = "BOOLEAN"
+	SQLiteText    = "TEXT"
+)
+
+// sqliteTypes maps PostgreSQL type names, as reported in the result
+// metadata, to SQLite column types. Unlisted types are stored as TEXT.
+var sqliteTypes = map[string]string{
+	"INT2":    SQLiteInteger,
+	"INT4":    SQLiteInteger,
+	"INT8":    SQLiteInteger,
+	"OID":     SQLiteInteger,
+	"FLOAT4":  SQLiteReal,
+	"FLOAT8":  SQLiteReal,
+	"NUMERIC": SQLiteNumeric,
+	"BOOL":    SQLiteBoolean,
+}
+
+// writeSQLiteBundle collects into a temporary archive and converts it into
+// a single SQLite file at path. Returns the number of collectors that
+// produced output.
+func writeSQLiteBundle(cfg *Config, path string) (int, error) {
+	tmp, err := os.CreateTemp("", "radar-*.zip")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	closeErrCheck(tmp, "temporary archive")
+	defer func() {
+		if err := os.Remove(tmpPath); err != nil {
+			errorLog.Printf("Failed to remove temporary archive: %v", err)
+		}
+	}()
+
+	cfg.ColumnTypes = make(map[string][]string)
+	totalCollected, err := writeArchive(cfg, tmpPath)
+	if err != nil {
+		return totalCollected, err
+	}
+
+	a, err := loadArchive(tmpPath)
+	if err != nil {
+		return totalCollected, err
+	}
+	if err := archiveToSQLite(a, cfg.ColumnTypes, path); err != nil {
+		return totalCollected, fmt.Errorf("failed to write SQLite bundle: %w", err)
+	}
+	return totalCollected, nil
+}
+
+// archiveToSQLite writes an archive as a SQLite database. TSV entries become
+// tables named and merged as by radar import, typed from the recorded
+// PostgreSQL column types or, failing that, from their values; all other
+// entries become rows of the files table.
+func archiveToSQLite(a *radarArchive, columnTypes map[string][]string, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer closeErrCheck(db, "SQLite database")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// No-op after a successful commit
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("CREATE TABLE " + quoteIdent(SQLiteFilesTable) + " (path TEXT PRIMARY KEY, content TEXT)"); err != nil {
+		return err
+	}
+	insertFile, err := tx.Prepare("INSERT INTO " + quoteIdent(SQLiteFilesTable) + " (path, content) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer closeErrCheck(insertFile, "SQLite statement")
+
+	// Column types of the tables created so far
+	tables := make(map[string]map[string]string)
+
+	for _, name := range a.Names {
+		var t *tsvTable
+		if strings.HasSuffix(name, ".tsv") {
+			t, _ = parseTSV(a.Files[name])
+		}
+		if t == nil || len(t.Columns) == 0 {
+			if _, err := insertFile.Exec(name, string(a.Files[name])); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			continue
+		}
+
+		table, datname := importTableName(name)
+		types := sqliteColumnTypes(t, columnTypes[name])
+		header, rows := t.Columns, t.Rows
+		if datname != "" {
+			header = append([]string{"datname"}, header...)
+			types = append([]string{SQLiteText}, types...)
+			rows = make([][]string, len(t.Rows))
+			for i, row := range t.Rows {
+				rows[i] = append([]string{datname}, row...)
+			}
+		}
+		columns := importColumns(header)
+
+		if err := sqliteEnsureTable(tx, table, columns, types, tables); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := sqliteInsertRows(tx, table, columns, tables[table], rows); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sqliteColumnTypes returns the SQLite type of each TSV column, from the
+// PostgreSQL types if recorded, otherwise inferred from the values.
+func sqliteColumnTypes(t *tsvTable, pgTypes []string) []string {
+	types := make([]string, len(t.Columns))
+	for i := range t.Columns {
+		if len(pgTypes) == len(t.Columns) && pgTypes[i] != "" {
+			types[i] = sqliteTypes[strings.ToUpper(pgTypes[i])]
+			if types[i] == "" {
+				types[i] = SQLiteText
+			}
+			continue
+		}
+		types[i] = inferSQLiteType(t, i)
+	}
+	return types
+}
+
+// inferSQLiteType types a column from its non-empty values.
+func inferSQLiteType(t *tsvTable, col int) string {
+	result := ""
+	for _, row := range t.Rows {
+		v := row[col]
+		if v == "" {
+			continue
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if result == "" {
+				result = SQLiteInteger
+			}
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			result = SQLiteReal
+			continue
+		}
+		return SQLiteText
+	}
+	if result == "" {
+		return SQLiteText
+	}
+	return result
+}
+
+// sqliteEnsureTable creates a table, or adds the columns missing from a
+// table created earlier in the conversion.
+func sqliteEnsureTable(tx *sql.Tx, table string, columns, types []string, tables map[string]map[string]string) error {
+	existing, ok := tables[table]
+	if !ok {
+		existing = make(map[string]string, len(columns))
+		defs := make([]string, len(columns))
+		for i, c := range columns {
+			defs[i] = quoteIdent(c) + " " + types[i]
+			existing[c] = types[i]
+		}
+		if _, err := tx.Exec("CREATE TABLE " + quoteIdent(table) + " (" + strings.Join(defs, ", ") + ")"); err != nil {
+			return fmt.Errorf("creating table %s: %w", table, err)
+		}
+		tables[table] = existing
+		return nil
+	}
+	for i, c := range columns {
+		if _, ok := existing[c]; ok {
+			continue
+		}
+		if _, err := tx.Exec("ALTER TABLE " + quoteIdent(table) + " ADD COLUMN " + quoteIdent(c) + " " + types[i]); err != nil {
+			return fmt.Errorf("extending table %s: %w", table, err)
+		}
+		existing[c] = types[i]
+	}
+	return nil
+}
+
+// sqliteInsertRows inserts rows with a prepared statement. Empty fields are
+// stored as NULL and booleans as 0 or 1; SQLite's type affinity converts
+// numeric text.
+func sqliteInsertRows(tx *sql.Tx, table string, columns []string, types map[string]string, rows [][]string) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	stmt, err := tx.Prepare("INSERT INTO " + quoteIdent(table) + " (" + strings.Join(quoted, ", ") +
+		") VALUES (" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")")
+	if err != nil {
+		return err
+	}
+	defer closeErrCheck(stmt, "SQLite statement")
+
+	args := make([]interface{}, len(columns))
+	for _, row := range rows {
+		for i, c := range columns {
+			args[i] = nil
+			if i >= len(row) || row[i] == "" {
+				continue
+			}
+			args[i] = row[i]
+			if types[c] == SQLiteBoolean {
+				args[i] = isTrue(row[i])
+			}
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestArchiveToSQLite verifies typed tables, merged per-database tables and the files table
+func TestArchiveToSQLite(t *testing.T) {
+	a := testArchive(map[string]string{
+		"postgresql/configuration.tsv":  "name\tsetting\tpending_restart\nwork_mem\t4096\tf\nshared_buffers\t16384\tt\n",
+		"databases/app/tables.tsv":      "schemaname\ttablename\nmy\"schema\torders\n",
+		"databases/postgres/tables.tsv": "schemaname\ttablename\thasindexes\npublic\tt\t\n",
+		ManifestPath:                    "category\tname\tpath\tstatus\tduration_ms\terror\nsystem\tuname\tsystem/uname.out\tok\t1.5\t\n",
+		"system/uname.out":              "Linux db1\n",
+	})
+	types := map[string][]string{
+		"postgresql/configuration.tsv": {"TEXT", "INT8", "BOOL"},
+	}
+
+	path := filepath.Join(t.TempDir(), "radar.sqlite")
+	if err := archiveToSQLite(a, types, path); err != nil {
+		t.Fatalf("archiveToSQLite failed: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeErrCheck(db, "SQLite database")
+
+	var setting int64
+	var pending bool
+	if err := db.QueryRow(`SELECT setting, pending_restart FROM postgresql_configuration WHERE name = 'shared_buffers'`).Scan(&setting, &pending); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if setting != 16384 || !pending {
+		t.Errorf("unexpected typed values %d, %v", setting, pending)
+	}
+
+	var settingType, durationType string
+	if err := db.QueryRow(`SELECT typeof(setting) FROM postgresql_configuration LIMIT 1`).Scan(&settingType); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT typeof(duration_ms) FROM manifest`).Scan(&durationType); err != nil {
+		t.Fatal(err)
+	}
+	if settingType != "integer" || durationType != "real" {
+		t.Errorf("unexpected storage types %q, %q", settingType, durationType)
+	}
+
+	var count, nulls int
+	if err := db.QueryRow(`SELECT count(*), count(*) - count(hasindexes) FROM databases_tables`).Scan(&count, &nulls); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || nulls != 2 {
+		t.Errorf("expected 2 merged rows with NULL hasindexes, got %d, %d", count, nulls)
+	}
+
+	var content string
+	if err := db.QueryRow(`SELECT content FROM files WHERE path = 'system/uname.out'`).Scan(&content); err != nil {
+		t.Fatal(err)
+	}
+	if content != "Linux db1\n" {
+		t.Errorf("unexpected file content %q", content)
+	}
+}
+
+// TestRowsTSVColumnTypes verifies query results record their column types
+func TestRowsTSVColumnTypes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("pid").OfType("INT4", 0),
+		sqlmock.NewColumn("state").OfType("TEXT", ""),
+	).AddRow(1, "active"))
+
+	var out sample
+	if err := pgQueryToTSV(db, "SELECT pid, state", &out, tsvOptions{Header: true, PrefixName: SampleTimestampColumn, PrefixValue: "T1"}); err != nil {
+		t.Fatalf("pgQueryToTSV failed: %v", err)
+	}
+	expected := []string{"TEXT", "INT4", "TEXT"}
+	if len(out.columnTypes) != len(expected) {
+		t.Fatalf("expected types %v, got %v", expected, out.columnTypes)
+	}
+	for i := range expected {
+		if out.columnTypes[i] != expected[i] {
+			t.Errorf("column %d: expected %q, got %q", i, expected[i], out.columnTypes[i])
+		}
+	}
+}
@@ -0,0 +1,260 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatementsInfoPath records where pg_stat_statements was read and its
+// pg_stat_statements_info counters
+const StatementsInfoPath = "postgresql/stat_statements_info.tsv"
+
+// statementsDimensions are the orderings of the top-N pg_stat_statements
+// collectors (sorted alphabetically by name). Each lists the columns it sums
+// for every extension version that has them, newest first.
+var statementsDimensions = []struct {
+	name    string
+	columns [][]string
+}{
+	{"blks_read", [][]string{{"shared_blks_read", "local_blks_read"}}},
+	{"calls", [][]string{{"calls"}}},
+	{"io_time", [][]string{
+		{"shared_blk_read_time", "shared_blk_write_time", "local_blk_read_time", "local_blk_write_time", "temp_blk_read_time", "temp_blk_write_time"}, // 1.11+
+		{"blk_read_time", "blk_write_time", "temp_blk_read_time", "temp_blk_write_time"},                                                              // 1.10
+		{"blk_read_time", "blk_write_time"},
+	}},
+	{"max_time", [][]string{{"max_exec_time"}, {"max_time"}}},
+	{"mean_time", [][]string{{"mean_exec_time"}, {"mean_time"}}},
+	{"plan_time", [][]string{{"total_plan_time"}}}, // 1.8+
+	{"rows", [][]string{{"rows"}}},
+	{"temp_blks", [][]string{{"temp_blks_read", "temp_blks_written"}}},
+	{"total_time", [][]string{{"total_exec_time"}, {"total_time"}}},
+	{"wal_bytes", [][]string{{"wal_bytes"}}}, // 1.8+
+}
+
+// statementsSource locates pg_stat_statements once for all its collectors.
+// The view only exists in the databases where the extension was created,
+// but it reports the statements of every database, so it is read from the
+// connection database or else from the first database that has it.
+type statementsSource struct {
+	db       *sql.DB
+	located  bool
+	err      error
+	database string // Empty for the connection database
+	schema   string
+	columns  map[string]bool
+	hasInfo  bool // pg_stat_statements_info, 1.9+
+}
+
+// getStatementsTasks returns the pg_stat_statements collectors: the
+// location and pg_stat_statements_info, then the top statements by each
+// dimension.
+func getStatementsTasks(db *sql.DB) []CollectionTask {
+	src := &statementsSource{db: db}
+	tasks := []CollectionTask{{
+		Category:    "postgresql",
+		Name:        "stat_statements_info",
+		ArchivePath: StatementsInfoPath,
+		Collector:   src.infoCollector,
+		QueryResult: true,
+	}}
+	for _, d := range statementsDimensions {
+		columns := d.columns
+		tasks = append(tasks, CollectionTask{
+			Category:    "postgresql",
+			Name:        "stat_statements_" + d.name,
+			ArchivePath: "postgresql/stat_statements_" + d.name + ".tsv",
+			Collector: func(cfg *Config, w io.Writer) error {
+				return src.collectTop(cfg, columns, w)
+			},
+			QueryResult: true,
+		})
+	}
+	return tasks
+}
+
+// locate finds pg_stat_statements on the first call and returns the
+// outcome of that search on every call.
+func (s *statementsSource) locate(cfg *Config) error {
+	if !s.located {
+		s.located = true
+		s.err = s.find(cfg)
+	}
+	return s.err
+}
+
+// find looks for the extension in the connection database, then in the
+// other databases that accept connections.
+func (s *statementsSource) find(cfg *Config) error {
+	if s.db == nil {
+		return fmt.Errorf("PostgreSQL not initialized")
+	}
+	found, err := s.inspect(s.db)
+	if err != nil || found {
+		return err
+	}
+
+	rows, err := s.db.Query("SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate AND datname <> current_database() ORDER BY datname")
+	if err != nil {
+		return fmt.Errorf("querying databases: %w", err)
+	}
+	var databases []string
+	for rows.Next() {
+		var dbname string
+		if err := rows.Scan(&dbname); err != nil {
+			closeErrCheck(rows, "database list query rows")
+			return fmt.Errorf("scanning database name: %w", err)
+		}
+		databases = append(databases, dbname)
+	}
+	closeErrCheck(rows, "database list query rows")
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating databases: %w", err)
+	}
+
+	for _, dbname := range databases {
+		db, err := sql.Open("pgx", cfg.ConnectionString(dbname))
+		if err != nil {
+			continue
+		}
+		found, err := s.inspect(db)
+		closeErrCheck(db, "database connection")
+		if err == nil && found {
+			s.database = dbname
+			if cfg.Verbose {
+				infoLog.Printf("pg_stat_statements is read from database %s", dbname)
+			}
+			return nil
+		}
+	}
+	return NewSkipError("pg_stat_statements is not installed in any database")
+}
+
+// inspect reports whether the database of db has the extension, and reads
+// its schema and the columns of its version.
+func (s *statementsSource) inspect(db *sql.DB) (bool, error) {
+	err := db.QueryRow(`SELECT n.nspname,
+       to_regclass(quote_ident(n.nspname) || '.pg_stat_statements_info') IS NOT NULL
+FROM pg_extension e
+JOIN pg_namespace n ON n.oid = e.extnamespace
+WHERE e.extname = 'pg_stat_statements'`).Scan(&s.schema, &s.hasInfo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("querying extensions: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT attname
+FROM pg_attribute
+WHERE attrelid = to_regclass(quote_ident($1) || '.pg_stat_statements')
+  AND attnum > 0
+  AND NOT attisdropped`, s.schema)
+	if err != nil {
+		return false, fmt.Errorf("querying pg_stat_statements columns: %w", err)
+	}
+	defer closeErrCheck(rows, "column query rows")
+	s.columns = make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, fmt.Errorf("scanning column name: %w", err)
+		}
+		s.columns[name] = true
+	}
+	return true, rows.Err()
+}
+
+// orderBy returns the sum of the first column set the installed version
+// has, or "" if it has none of them.
+func (s *statementsSource) orderBy(alternatives [][]string) string {
+	for _, columns := range alternatives {
+		terms := make([]string, 0, len(columns))
+		for _, c := range columns {
+			if !s.columns[c] {
+				break
+			}
+			terms = append(terms, "s."+c)
+		}
+		if len(terms) == len(columns) {
+			return strings.Join(terms, " + ")
+		}
+	}
+	return ""
+}
+
+// infoCollector writes the database and schema pg_stat_statements is read
+// from, its version and settings, and the pg_stat_statements_info counters.
+func (s *statementsSource) infoCollector(cfg *Config, w io.Writer) error {
+	if err := s.locate(cfg); err != nil {
+		return err
+	}
+	info := "NULL::bigint AS dealloc, NULL::timestamptz AS stats_reset"
+	from := ""
+	if s.hasInfo {
+		info = "i.dealloc, i.stats_reset"
+		from = fmt.Sprintf("\nCROSS JOIN %s.pg_stat_statements_info i", quoteIdent(s.schema))
+	}
+	query := fmt.Sprintf(`SELECT current_database() AS database,
+       n.nspname AS schema,
+       e.extversion,
+       current_setting('pg_stat_statements.max', true) AS max,
+       current_setting('pg_stat_statements.track', true) AS track,
+       %s
+FROM pg_extension e
+JOIN pg_namespace n ON n.oid = e.extnamespace%s
+WHERE e.extname = 'pg_stat_statements'`, info, from)
+	return s.query(cfg, query, w)
+}
+
+// collectTop writes the --top-n statements ordered by the sum of columns,
+// with every column of the installed version and the database and role
+// names.
+func (s *statementsSource) collectTop(cfg *Config, columns [][]string, w io.Writer) error {
+	if err := s.locate(cfg); err != nil {
+		return err
+	}
+	order := s.orderBy(columns)
+	if order == "" {
+		return NewSkipError(fmt.Sprintf("pg_stat_statements has no %s column", strings.Join(columns[0], ", ")))
+	}
+	query := fmt.Sprintf(`SELECT d.datname, r.rolname, s.*
+FROM %s.pg_stat_statements s
+LEFT JOIN pg_database d ON d.oid = s.dbid
+LEFT JOIN pg_roles r ON r.oid = s.userid
+ORDER BY %s DESC NULLS LAST
+LIMIT %s`, quoteIdent(s.schema), order, TopNPlaceholder)
+	return s.query(cfg, query, w)
+}
+
+// query runs a query in the database pg_stat_statements is read from. The
+// view fails when the library is not in shared_preload_libraries, which is
+// a skip.
+func (s *statementsSource) query(cfg *Config, query string, w io.Writer) error {
+	var err error
+	if s.database == "" {
+		err = pgQueryCollector(s.db, query, nil)(cfg, w)
+	} else {
+		err = execPGQueryOnDB(s.database, cfg, query, nil, w)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "55000" { // object_not_in_prerequisite_state
+		return NewSkipError(pgErr.Message)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// statementsTask returns the pg_stat_statements collector of a dimension
+func statementsTask(t *testing.T, tasks []CollectionTask, name string) CollectionTask {
+	t.Helper()
+	for _, task := range tasks {
+		if task.Name == "stat_statements_"+name {
+			return task
+		}
+	}
+	t.Fatalf("stat_statements_%s not found", name)
+	return CollectionTask{}
+}
+
+// TestStatementsVersionColumns verifies the orderings use the columns of the
+// installed version and are skipped when it lacks them
+func TestStatementsVersionColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	// Version 1.7 (PG12): total_time, blk_read_time, no pg_stat_statements_info
+	mock.ExpectQuery("FROM pg_extension").WillReturnRows(sqlmock.NewRows([]string{"nspname", "has_info"}).AddRow("stats", false))
+	columns := sqlmock.NewRows([]string{"attname"})
+	for _, c := range strings.Fields("userid dbid queryid query calls total_time min_time max_time mean_time stddev_time rows shared_blks_hit shared_blks_read local_blks_read temp_blks_read temp_blks_written blk_read_time blk_write_time") {
+		columns.AddRow(c)
+	}
+	mock.ExpectQuery("FROM pg_attribute").WithArgs("stats").WillReturnRows(columns)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "stats".pg_stat_statements s`) + `(?s).*` +
+		regexp.QuoteMeta("ORDER BY s.total_time DESC NULLS LAST\nLIMIT 25")).
+		WillReturnRows(sqlmock.NewRows([]string{"datname", "rolname", "query"}).AddRow("shop", "app", "SELECT 1"))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY s.blk_read_time + s.blk_write_time DESC")).
+		WillReturnRows(sqlmock.NewRows([]string{"datname"}))
+	mock.ExpectQuery(regexp.QuoteMeta("NULL::bigint AS dealloc")).
+		WillReturnRows(sqlmock.NewRows([]string{"database", "extversion"}).AddRow("postgres", "1.7"))
+
+	tasks := getStatementsTasks(db)
+	cfg := &Config{TopN: 25}
+	var buf bytes.Buffer
+	if err := statementsTask(t, tasks, "total_time").Collector(cfg, &buf); err != nil {
+		t.Fatalf("total_time failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "shop\tapp\tSELECT 1") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+	if err := statementsTask(t, tasks, "io_time").Collector(cfg, &bytes.Buffer{}); err != nil {
+		t.Fatalf("io_time failed: %v", err)
+	}
+	var skipErr SkipError
+	if err := statementsTask(t, tasks, "wal_bytes").Collector(cfg, &bytes.Buffer{}); !errors.As(err, &skipErr) {
+		t.Errorf("expected wal_bytes to be skipped, got %v", err)
+	}
+	if err := statementsTask(t, tasks, "info").Collector(cfg, &bytes.Buffer{}); err != nil {
+		t.Fatalf("info failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestStatementsNotInstalled verifies the search runs once and every
+// collector is skipped when no database has the extension or its library
+// is not loaded
+func TestStatementsNotInstalled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer closeErrCheck(db, "mock db")
+
+	mock.ExpectQuery("FROM pg_extension").WillReturnRows(sqlmock.NewRows([]string{"nspname", "has_info"}))
+	mock.ExpectQuery("FROM pg_database").WillReturnRows(sqlmock.NewRows([]string{"datname"}))
+
+	tasks := getStatementsTasks(db)
+	for _, name := range []string{"calls", "info"} {
+		var skipErr SkipError
+		if err := statementsTask(t, tasks, name).Collector(&Config{}, &bytes.Buffer{}); !errors.As(err, &skipErr) {
+			t.Errorf("%s: expected skip, got %v", name, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// Installed but not in shared_preload_libraries
+	mock.ExpectQuery("FROM pg_extension").WillReturnRows(sqlmock.NewRows([]string{"nspname", "has_info"}).AddRow("public", true))
+	mock.ExpectQuery("FROM pg_attribute").WillReturnRows(sqlmock.NewRows([]string{"attname"}).AddRow("calls"))
+	mock.ExpectQuery("pg_stat_statements s").WillReturnError(&pgconn.PgError{Code: "55000", Message: `pg_stat_statements must be loaded via "shared_preload_libraries"`})
+	var skipErr SkipError
+	if err := statementsTask(t, getStatementsTasks(db), "calls").Collector(&Config{}, &bytes.Buffer{}); !errors.As(err, &skipErr) {
+		t.Errorf("expected skip, got %v", err)
+	}
+}
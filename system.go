@@ -18,6 +18,7 @@ func getSystemTasks() []CollectionTask {
 	// Add platform-specific tasks (linux or darwin build tag)
 	tasks = append(tasks, buildCommandTasks("system", systemCommandTasks)...)
 	tasks = append(tasks, buildFileTasks("system", systemFileTasks)...)
+	tasks = append(tasks, getSamplerTasks()...)
 
 	// Add shared cross-platform tasks
 	tasks = append(tasks, buildCommandTasks("system", sharedCommandTasks)...)
@@ -12,11 +12,29 @@
 
 package main
 
+import "database/sql"
+
 // getContainerTasks is nil on macOS (container detection is Linux-only)
 func getContainerTasks() []CollectionTask {
 	return nil
 }
 
+// getBackendOSTasks is nil on macOS (backend process stats read Linux /proc files)
+func getBackendOSTasks(db *sql.DB) []CollectionTask {
+	return nil
+}
+
+// getSamplerTasks is nil on macOS (native samplers read Linux /proc files)
+func getSamplerTasks() []CollectionTask {
+	return nil
+}
+
+// systemCounterTasks is empty on macOS (--delta reads Linux /proc counters)
+var systemCounterTasks []SimpleCounterTask
+
+// systemGaugeTasks is empty on macOS (radar serve reads Linux /proc and cgroup values)
+var systemGaugeTasks []SimpleGaugeTask
+
 // macOS-specific command tasks (sorted alphabetically by name)
 var systemCommandTasks = []SimpleCommandTask{
 	{
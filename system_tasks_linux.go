@@ -85,12 +85,6 @@ var systemCommandTasks = []SimpleCommandTask{
 		Command:     "ip",
 		Args:        []string{"-o", "address"},
 	},
-	{
-		Name:        "iostat",
-		ArchivePath: "system/iostat.out",
-		Command:     "iostat",
-		Args:        []string{"-x", "1", "5"},
-	},
 	{
 		Name:        "ip-addr",
 		ArchivePath: "system/ip_addr.out",
@@ -139,24 +133,12 @@ var systemCommandTasks = []SimpleCommandTask{
 		Command:     "lspci",
 		Args:        []string{},
 	},
-	{
-		Name:        "mpstat",
-		ArchivePath: "system/mpstat.out",
-		Command:     "mpstat",
-		Args:        []string{"-P", "ALL", "1", "5"},
-	},
 	{
 		Name:        "netstat-stats",
 		ArchivePath: "system/netstat_stats.out",
 		Command:     "netstat",
 		Args:        []string{"-s"},
 	},
-	{
-		Name:        "nfsiostat",
-		ArchivePath: "system/nfsiostat.out",
-		Command:     "nfsiostat",
-		Args:        []string{},
-	},
 	{
 		Name:        "numactl",
 		ArchivePath: "system/numactl.out",
@@ -223,12 +205,6 @@ var systemCommandTasks = []SimpleCommandTask{
 		Command:     "sh",
 		Args:        []string{"-c", "systemctl status 'postgresql*' 2>/dev/null || systemctl status 'postgres*' 2>/dev/null"},
 	},
-	{
-		Name:        "sar",
-		ArchivePath: "system/sar.out",
-		Command:     "sar",
-		Args:        []string{"-A"},
-	},
 	{
 		Name:        "sestatus",
 		ArchivePath: "system/sestatus.out",
@@ -277,12 +253,6 @@ var systemCommandTasks = []SimpleCommandTask{
 		Command:     "tuned-adm",
 		Args:        []string{"list"},
 	},
-	{
-		Name:        "vmstat-command",
-		ArchivePath: "system/vmstat-command.out",
-		Command:     "vmstat",
-		Args:        []string{"1", "10"},
-	},
 	{
 		Name:        "clocksource",
 		ArchivePath: "system/sys/clocksource.out",
@@ -549,6 +519,83 @@ var systemFileTasks = []SimpleFileTask{
 	},
 }
 
+// Interval samplers with a native /proc fallback (sorted alphabetically by name)
+var systemSamplerTasks = []SimpleSamplerTask{
+	{
+		Name:        "iostat",
+		ArchivePath: "system/iostat.out",
+		TSVPath:     "system/iostat.tsv",
+		Command:     "iostat",
+		Args:        []string{"-x", "1", "5"},
+		Count:       5,
+		Report:      iostatReport,
+	},
+	{
+		Name:        "mpstat",
+		ArchivePath: "system/mpstat.out",
+		TSVPath:     "system/mpstat.tsv",
+		Command:     "mpstat",
+		Args:        []string{"-P", "ALL", "1", "5"},
+		Count:       5,
+		Report:      mpstatReport,
+	},
+	{
+		Name:        "nfsiostat",
+		ArchivePath: "system/nfsiostat.out",
+		TSVPath:     "system/nfsiostat.tsv",
+		Command:     "nfsiostat",
+		Args:        []string{},
+		Count:       5,
+		Report:      nfsiostatReport,
+	},
+	{
+		Name:        "sar",
+		ArchivePath: "system/sar.out",
+		TSVPath:     "system/sar_net_dev.tsv",
+		Command:     "sar",
+		Args:        []string{"-A"},
+		Count:       5,
+		CommandOnly: true, // sar -A is the daily history, not /proc/net/dev
+		Report:      netdevReport,
+	},
+	{
+		Name:        "vmstat-command",
+		ArchivePath: "system/vmstat-command.out",
+		TSVPath:     "system/vmstat.tsv",
+		Command:     "vmstat",
+		Args:        []string{"1", "10"},
+		Count:       10,
+		Report:      vmstatReport,
+	},
+}
+
+// Cumulative kernel counters sampled twice with --delta (sorted alphabetically by name)
+var systemCounterTasks = []SimpleCounterTask{
+	{
+		Name:        "diskstats",
+		ArchivePath: "system/proc/diskstats_rate.tsv",
+		Keys:        []string{"device"},
+		Gauges:      []string{"ios_in_progress"},
+		Snapshot: procCounterSnapshot(func(c ...string) *tsvTable {
+			return parseDiskstats(c[0])
+		}, "/proc/diskstats"),
+	},
+	{
+		Name:        "netstat-stats",
+		ArchivePath: "system/netstat_stats_rate.tsv",
+		Keys:        []string{"protocol", "name"},
+		Snapshot:    procCounterSnapshot(parseNetstat, "/proc/net/snmp", "/proc/net/netstat"),
+	},
+	{
+		Name:        "proc-vmstat",
+		ArchivePath: "system/proc/vmstat_rate.tsv",
+		Keys:        []string{"name"},
+		Snapshot: procCounterSnapshot(func(c ...string) *tsvTable {
+			return parseVMStat(c[0])
+		}, "/proc/vmstat"),
+	},
+}
+
 // Container-only command tasks (only included when isContainer() returns true)
 var containerCommandTasks = []SimpleCommandTask{
 	{
@@ -0,0 +1,288 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Trigger Defaults
+const (
+	DefaultWatchInterval   = 10 * time.Second
+	DefaultTriggerCooldown = 30 * time.Minute
+	DefaultTriggerProfile  = "full"
+	TriggerQueryTimeout    = 5 * time.Second
+)
+
+// Watched signal names, as written to trigger.tsv
+const (
+	SignalActiveBackends = "active_backends"
+	SignalLockWaits      = "lock_waits"
+	SignalReplicationLag = "replication_lag_bytes"
+	SignalPSICPU         = "psi_cpu_some_avg10"
+	SignalPSIIO          = "psi_io_some_avg10"
+	SignalPSIMemory      = "psi_memory_some_avg10"
+	SignalLoad1          = "load1"
+	SignalDataDirFreePct = "data_dir_free_pct"
+)
+
+// triggerSignalsQuery reads the PostgreSQL signals in a single round trip.
+// On a standby the lag is receive versus replay, on a primary the largest
+// replay lag of its standbys.
+const triggerSignalsQuery = `SELECT
+    (SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND backend_type = 'client backend') AS active_backends,
+    (SELECT count(*) FROM pg_locks WHERE NOT granted) AS lock_waits,
+    coalesce(CASE WHEN pg_is_in_recovery()
+        THEN pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())
+        ELSE (SELECT max(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn)) FROM pg_stat_replication)
+    END, 0) AS replication_lag_bytes`
+
+// Trigger profiles limit what a triggered collection gathers
+var triggerProfiles = map[string]bool{"full": true, "postgres": true, "system": true}
+
+// TriggerThresholds holds the user-configured trigger levels. Zero disables a signal.
+type TriggerThresholds struct {
+	ActiveBackends int
+	LockWaits      int
+	ReplicationLag byteSize
+	PSICPU         float64
+	PSIIO          float64
+	PSIMemory      float64
+	Load1          float64
+	DataDirFreePct float64 // Fires when free space drops below this percentage
+
+	WatchInterval time.Duration
+	Cooldown      time.Duration
+	Profile       string
+}
+
+// TriggerReason records a crossed threshold that started a collection
+type TriggerReason struct {
+	Time      time.Time
+	Signal    string
+	Value     float64
+	Threshold float64
+	Below     bool // Threshold is a lower bound
+}
+
+// String describes the crossing, e.g. "lock_waits=12 >= 10".
+func (r TriggerReason) String() string {
+	op := ">="
+	if r.Below {
+		op = "<"
+	}
+	return fmt.Sprintf("%s=%s %s %s", r.Signal, formatSignalValue(r.Value), op, formatSignalValue(r.Threshold))
+}
+
+// registerTriggerFlags defines the trigger threshold flags on fs.
+func registerTriggerFlags(fs *flag.FlagSet, t *TriggerThresholds) {
+	fs.IntVar(&t.ActiveBackends, "trigger-active-backends", 0, "collect when active client backends reach this count")
+	fs.IntVar(&t.LockWaits, "trigger-lock-waits", 0, "collect when ungranted locks reach this count")
+	fs.Var(&t.ReplicationLag, "trigger-replication-lag", "collect when replication lag reaches this size, e.g. 1G")
+	fs.Float64Var(&t.PSICPU, "trigger-psi-cpu", 0, "collect when /proc/pressure/cpu some avg10 reaches this percentage")
+	fs.Float64Var(&t.PSIIO, "trigger-psi-io", 0, "collect when /proc/pressure/io some avg10 reaches this percentage")
+	fs.Float64Var(&t.PSIMemory, "trigger-psi-memory", 0, "collect when /proc/pressure/memory some avg10 reaches this percentage")
+	fs.Float64Var(&t.Load1, "trigger-load", 0, "collect when the 1-minute load average reaches this value")
+	fs.Float64Var(&t.DataDirFreePct, "trigger-disk-free-pct", 0, "collect when free space on the data directory drops below this percentage")
+	fs.DurationVar(&t.WatchInterval, "watch-interval", DefaultWatchInterval, "interval between trigger signal checks")
+	fs.DurationVar(&t.Cooldown, "trigger-cooldown", DefaultTriggerCooldown, "minimum time between triggered collections")
+	fs.StringVar(&t.Profile, "trigger-profile", DefaultTriggerProfile, "what a triggered collection gathers (full, postgres, system)")
+}
+
+// validate checks trigger settings.
+func (t *TriggerThresholds) validate() error {
+	if t.ActiveBackends < 0 || t.LockWaits < 0 || t.PSICPU < 0 || t.PSIIO < 0 ||
+		t.PSIMemory < 0 || t.Load1 < 0 || t.DataDirFreePct < 0 || t.DataDirFreePct > 100 {
+		return fmt.Errorf("trigger thresholds must not be negative (and --trigger-disk-free-pct at most 100)")
+	}
+	if t.WatchInterval <= 0 {
+		return fmt.Errorf("--watch-interval must be positive")
+	}
+	if t.Cooldown < 0 {
+		return fmt.Errorf("--trigger-cooldown must not be negative")
+	}
+	if !triggerProfiles[t.Profile] {
+		return fmt.Errorf("invalid --trigger-profile %q: must be one of full, postgres, system", t.Profile)
+	}
+	return nil
+}
+
+// Enabled reports whether any trigger threshold is configured.
+func (t *TriggerThresholds) Enabled() bool {
+	return t.ActiveBackends > 0 || t.LockWaits > 0 || t.ReplicationLag > 0 ||
+		t.PSICPU > 0 || t.PSIIO > 0 || t.PSIMemory > 0 || t.Load1 > 0 || t.DataDirFreePct > 0
+}
+
+// evaluate compares measured signal values against the thresholds.
+// Signals that could not be measured are ignored.
+func (t *TriggerThresholds) evaluate(values map[string]float64, now time.Time) []TriggerReason {
+	checks := []struct {
+		signal    string
+		threshold float64
+		below     bool
+	}{
+		{SignalActiveBackends, float64(t.ActiveBackends), false},
+		{SignalLockWaits, float64(t.LockWaits), false},
+		{SignalReplicationLag, float64(t.ReplicationLag), false},
+		{SignalPSICPU, t.PSICPU, false},
+		{SignalPSIIO, t.PSIIO, false},
+		{SignalPSIMemory, t.PSIMemory, false},
+		{SignalLoad1, t.Load1, false},
+		{SignalDataDirFreePct, t.DataDirFreePct, true},
+	}
+
+	var reasons []TriggerReason
+	for _, c := range checks {
+		value, measured := values[c.signal]
+		if c.threshold <= 0 || !measured {
+			continue
+		}
+		if (c.below && value < c.threshold) || (!c.below && value >= c.threshold) {
+			reasons = append(reasons, TriggerReason{
+				Time:      now,
+				Signal:    c.signal,
+				Value:     value,
+				Threshold: c.threshold,
+				Below:     c.below,
+			})
+		}
+	}
+	return reasons
+}
+
+// applyProfile limits a collection config to the trigger profile.
+func (t *TriggerThresholds) applyProfile(cfg *Config) {
+	switch t.Profile {
+	case "postgres":
+		cfg.SkipSystem = true
+	case "system":
+		cfg.SkipPostgres = true
+	}
+}
+
+// readTriggerSignals measures the cheap signals watched between collections.
+// Signals that cannot be read on this host are left out of the result.
+func readTriggerSignals(cfg *Config, dataDir string) map[string]float64 {
+	values := make(map[string]float64)
+
+	if cfg.DB != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), TriggerQueryTimeout)
+		defer cancel()
+
+		var active, lockWaits, lag float64
+		err := cfg.DB.QueryRowContext(ctx, triggerSignalsQuery).Scan(&active, &lockWaits, &lag)
+		if err != nil {
+			if cfg.VeryVerbose {
+				infoLog.Printf("Trigger signals query failed: %v", err)
+			}
+		} else {
+			values[SignalActiveBackends] = active
+			values[SignalLockWaits] = lockWaits
+			values[SignalReplicationLag] = lag
+		}
+	}
+
+	for signal, path := range map[string]string{
+		SignalPSICPU:    "/proc/pressure/cpu",
+		SignalPSIIO:     "/proc/pressure/io",
+		SignalPSIMemory: "/proc/pressure/memory",
+	} {
+		if data, err := os.ReadFile(path); err == nil {
+			if v, ok := parsePSISomeAvg10(string(data)); ok {
+				values[signal] = v
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 0 {
+			if v, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				values[SignalLoad1] = v
+			}
+		}
+	}
+
+	if dataDir != "" {
+		if v, ok := freeSpacePct(dataDir); ok {
+			values[SignalDataDirFreePct] = v
+		}
+	}
+
+	return values
+}
+
+// parsePSISomeAvg10 extracts the "some avg10" value from a /proc/pressure file.
+func parsePSISomeAvg10(content string) (float64, bool) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(f, "avg10="); ok {
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					return n, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// freeSpacePct returns the percentage of space available to unprivileged
+// users on the filesystem holding path.
+func freeSpacePct(path string) (float64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil || st.Blocks == 0 {
+		return 0, false
+	}
+	return float64(st.Bavail) / float64(st.Blocks) * 100, true
+}
+
+// formatSignalValue renders a signal value without trailing zeros.
+func formatSignalValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// triggerTask returns the task writing trigger.tsv into a triggered archive.
+func triggerTask(reasons []TriggerReason) CollectionTask {
+	return CollectionTask{
+		Category:    "radar",
+		Name:        "trigger",
+		ArchivePath: "trigger.tsv",
+		Collector: func(cfg *Config, w io.Writer) error {
+			if _, err := io.WriteString(w, "triggered_at\tsignal\tvalue\tcondition\tthreshold\n"); err != nil {
+				return err
+			}
+			for _, r := range reasons {
+				condition := ">="
+				if r.Below {
+					condition = "<"
+				}
+				_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					r.Time.Format(SampleTimestampFormat), r.Signal,
+					formatSignalValue(r.Value), condition, formatSignalValue(r.Threshold))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,129 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTriggerEvaluate verifies threshold crossing, lower bounds and unmeasured signals
+func TestTriggerEvaluate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	thresholds := TriggerThresholds{
+		ActiveBackends: 100,
+		LockWaits:      10,
+		PSIIO:          20,
+		DataDirFreePct: 10,
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]float64
+		expected []string
+	}{
+		{"nothing crossed", map[string]float64{SignalActiveBackends: 5, SignalLockWaits: 0, SignalDataDirFreePct: 50}, nil},
+		{"count reached", map[string]float64{SignalActiveBackends: 100}, []string{SignalActiveBackends}},
+		{"lower bound", map[string]float64{SignalDataDirFreePct: 4.5}, []string{SignalDataDirFreePct}},
+		{"several signals", map[string]float64{SignalLockWaits: 12, SignalPSIIO: 35.2}, []string{SignalLockWaits, SignalPSIIO}},
+		{"disabled signal ignored", map[string]float64{SignalLoad1: 500}, nil},
+		{"unmeasured signal ignored", map[string]float64{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := thresholds.evaluate(tt.values, now)
+			if len(reasons) != len(tt.expected) {
+				t.Fatalf("expected %d reasons, got %d: %v", len(tt.expected), len(reasons), reasons)
+			}
+			for i, r := range reasons {
+				if r.Signal != tt.expected[i] {
+					t.Errorf("reason %d: expected %s, got %s", i, tt.expected[i], r.Signal)
+				}
+				if !r.Time.Equal(now) {
+					t.Errorf("reason %d: unexpected time %s", i, r.Time)
+				}
+			}
+		})
+	}
+}
+
+// TestParsePSISomeAvg10 verifies parsing of /proc/pressure files
+func TestParsePSISomeAvg10(t *testing.T) {
+	content := "some avg10=12.34 avg60=5.00 avg300=1.00 total=123456\n" +
+		"full avg10=3.21 avg60=1.00 avg300=0.50 total=65432\n"
+
+	v, ok := parsePSISomeAvg10(content)
+	if !ok || v != 12.34 {
+		t.Errorf("expected 12.34, got %v (ok=%v)", v, ok)
+	}
+
+	if _, ok := parsePSISomeAvg10("garbage\n"); ok {
+		t.Error("expected no value for malformed content")
+	}
+}
+
+// TestTriggerTask verifies the trigger reason file written into the archive
+func TestTriggerTask(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	task := triggerTask([]TriggerReason{
+		{Time: ts, Signal: SignalLockWaits, Value: 12, Threshold: 10},
+		{Time: ts, Signal: SignalDataDirFreePct, Value: 4.5, Threshold: 10, Below: true},
+	})
+
+	if task.ArchivePath != "trigger.tsv" {
+		t.Errorf("unexpected archive path %q", task.ArchivePath)
+	}
+
+	var buf bytes.Buffer
+	if err := task.Collector(&Config{}, &buf); err != nil {
+		t.Fatalf("collector failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %q", buf.String())
+	}
+	if lines[0] != "triggered_at\tsignal\tvalue\tcondition\tthreshold" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "\tlock_waits\t12\t>=\t10") {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "\tdata_dir_free_pct\t4.5\t<\t10") {
+		t.Errorf("unexpected row %q", lines[2])
+	}
+}
+
+// TestTriggerValidation verifies trigger option validation
+func TestTriggerValidation(t *testing.T) {
+	if _, _, err := loadDaemonConfig([]string{"--skip-postgres", "--trigger-profile", "tiny"}); err == nil {
+		t.Error("expected error for unknown trigger profile")
+	}
+	if _, _, err := loadDaemonConfig([]string{"--skip-postgres", "--trigger-disk-free-pct", "150"}); err == nil {
+		t.Error("expected error for free space percentage above 100")
+	}
+
+	_, dcfg, err := loadDaemonConfig([]string{"--skip-postgres", "--trigger-lock-waits", "5", "--trigger-profile", "system"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dcfg.Triggers.Enabled() {
+		t.Error("expected triggers to be enabled")
+	}
+
+	cfg := &Config{}
+	dcfg.Triggers.applyProfile(cfg)
+	if !cfg.SkipPostgres || cfg.SkipSystem {
+		t.Errorf("system profile should skip PostgreSQL only, got %+v", cfg)
+	}
+}
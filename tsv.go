@@ -0,0 +1,126 @@
+/*-------------------------------------------------------------------------
+ *
+ * radar
+ *
+ * Portions copyright (c) 2026, pgEdge, Inc.
+ * This software is released under The PostgreSQL License
+ *
+ *-------------------------------------------------------------------------
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tsvTable is a parsed TSV file: a header line and rows of fields
+type tsvTable struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// quoteTSVField applies radar's TSV escaping: fields containing a tab,
+// newline, carriage return or double quote are wrapped in double quotes
+// with embedded quotes doubled
+func quoteTSVField(s string) string {
+	if strings.ContainsAny(s, "\t\n\r\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
+
+// parseTSV parses TSV written by rowsToTSV, undoing its quoting.
+// The first line is the header.
+func parseTSV(data []byte) (*tsvTable, error) {
+	var records [][]string
+	var record []string
+	var field strings.Builder
+
+	s := string(data)
+	for i := 0; i < len(s); {
+		// A field starting with a quote is always quoted, since unquoted
+		// fields never contain one
+		if field.Len() == 0 && s[i] == '"' {
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("line %d: unterminated quoted field", len(records)+1)
+				}
+				if s[i] == '"' {
+					if i+1 < len(s) && s[i+1] == '"' {
+						field.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				field.WriteByte(s[i])
+				i++
+			}
+			if i < len(s) && s[i] != '\t' && s[i] != '\n' {
+				return nil, fmt.Errorf("line %d: unexpected character after quoted field", len(records)+1)
+			}
+			continue
+		}
+
+		switch s[i] {
+		case '\t':
+			record = append(record, field.String())
+			field.Reset()
+		case '\n':
+			record = append(record, field.String())
+			field.Reset()
+			records = append(records, record)
+			record = nil
+		default:
+			field.WriteByte(s[i])
+		}
+		i++
+	}
+	if field.Len() > 0 || record != nil {
+		records = append(records, append(record, field.String()))
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty TSV")
+	}
+
+	// Pad short rows so every row can be indexed by column
+	t := &tsvTable{Columns: records[0], Rows: records[1:]}
+	for i, row := range t.Rows {
+		for len(row) < len(t.Columns) {
+			row = append(row, "")
+		}
+		t.Rows[i] = row
+	}
+	return t, nil
+}
+
+// columnIndex returns the position of a column, or -1 if absent.
+func (t *tsvTable) columnIndex(name string) int {
+	for i, c := range t.Columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeTSV writes the table using radar's TSV escaping.
+func (t *tsvTable) writeTSV(w io.Writer) error {
+	lines := append([][]string{t.Columns}, t.Rows...)
+	for _, fields := range lines {
+		quoted := make([]string, len(fields))
+		for i, f := range fields {
+			quoted[i] = quoteTSVField(f)
+		}
+		if _, err := io.WriteString(w, strings.Join(quoted, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}